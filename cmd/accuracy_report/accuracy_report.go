@@ -0,0 +1,53 @@
+// Compares a finished hunt against the known transmitter position and reports the accuracy of bearings and estimates
+package main
+
+import (
+	"flag"
+	"github.com/hsmade/OSM-ARDF/pkg/analysis"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	databaseURL = flag.String("database", os.Getenv("DATABASE"), "TimescaleDB url")
+	longitude   = flag.Float64("longitude", 0, "longitude of the transmitter")
+	latitude    = flag.Float64("latitude", 0, "latitude of the transmitter")
+	since       = flag.Duration("since", 24*time.Hour, "analyse the bearings of this period")
+	window      = flag.Duration("window", 5*time.Minute, "use the bearings of this period for each estimate")
+	fixDistance = flag.Float64("fix-distance", 100, "distance in metres from the transmitter that counts as a fix")
+	format      = flag.String("format", "json", "output format: json, stations-csv or estimates-csv")
+)
+
+func main() {
+	flag.Parse()
+
+	db := database.New(*databaseURL)
+	if db == nil {
+		log.Fatal("invalid database url")
+	}
+	if err := db.Connect(); err != nil {
+		log.Fatalf("failed to connect to database: %e", err)
+	}
+
+	lines, err := db.GetLines(*since)
+	if err != nil {
+		log.Fatalf("failed to get lines: %e", err)
+	}
+
+	report := analysis.NewReport(lines, *longitude, *latitude, *window, *fixDistance)
+	switch *format {
+	case "json":
+		err = report.WriteJSON(os.Stdout)
+	case "stations-csv":
+		err = report.WriteStationsCSV(os.Stdout)
+	case "estimates-csv":
+		err = report.WriteEstimatesCSV(os.Stdout)
+	default:
+		log.Fatalf("unknown format: %s", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
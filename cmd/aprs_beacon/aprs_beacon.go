@@ -0,0 +1,66 @@
+// Runs the APRS beacon that sends the estimated transmitter position as an APRS object
+package main
+
+import (
+	"flag"
+	"github.com/hsmade/OSM-ARDF/pkg/aprs"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	databaseURL = flag.String("database", os.Getenv("DATABASE"), "TimescaleDB url")
+	callsign    = flag.String("callsign", "", "callsign to send the object from (required)")
+	passcode    = flag.Int("passcode", -1, "APRS-IS passcode, calculated from the callsign when not set")
+	aprsIS      = flag.String("aprs-is", "", "APRS-IS server to send to, like rotate.aprs2.net:14580")
+	kiss        = flag.String("kiss", "", "KISS TNC to send to, like localhost:8001")
+	path        = flag.String("path", "WIDE2-1", "digipeater path, comma separated")
+	name        = flag.String("name", "FOX", "object name, at most 9 characters")
+	since       = flag.Duration("since", 5*time.Minute, "estimate from the bearings of this period")
+	minInterval = flag.Duration("min-interval", time.Minute, "minimal time between beacons")
+	maxInterval = flag.Duration("max-interval", 10*time.Minute, "maximal time between beacons")
+)
+
+func main() {
+	flag.Parse()
+	if *callsign == "" || (*aprsIS == "") == (*kiss == "") {
+		log.Fatal("need a callsign and either -aprs-is or -kiss")
+	}
+
+	db := database.New(*databaseURL)
+	if db == nil {
+		log.Fatal("invalid database url")
+	}
+	if err := db.Connect(); err != nil {
+		log.Fatalf("failed to connect to database: %e", err)
+	}
+
+	var sender aprs.Sender
+	var err error
+	if *aprsIS != "" {
+		if *passcode < 0 {
+			*passcode = aprs.Passcode(*callsign)
+		}
+		sender, err = aprs.NewISSender(*aprsIS, *callsign, *passcode)
+	} else {
+		sender, err = aprs.NewKISSSender(*kiss)
+	}
+	if err != nil {
+		log.Fatalf("failed to connect: %e", err)
+	}
+	defer sender.Close()
+
+	beacon := aprs.NewBeacon(db, sender, *callsign)
+	beacon.Name = *name
+	beacon.Since = *since
+	beacon.MinInterval = *minInterval
+	beacon.MaxInterval = *maxInterval
+	beacon.Path = nil
+	if *path != "" {
+		beacon.Path = strings.Split(*path, ",")
+	}
+	log.Fatal(beacon.Start())
+}
@@ -0,0 +1,51 @@
+// Runs the Cursor-on-Target publisher that sends stations, bearings and the estimate to ATAK and WinTAK
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/hsmade/OSM-ARDF/pkg/cot"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+)
+
+var (
+	databaseURL = flag.String("database", os.Getenv("DATABASE"), "TimescaleDB url")
+	network     = flag.String("network", "udp", "udp (for multicast) or tcp")
+	address     = flag.String("address", "239.2.3.1:6969", "address to send the events to")
+	since       = flag.Duration("since", time.Minute, "publish the bearings of this period")
+	interval    = flag.Duration("interval", 5*time.Second, "time between updates")
+)
+
+func main() {
+	flag.Parse()
+
+	db := database.New(*databaseURL)
+	if db == nil {
+		log.Fatal("invalid database url")
+	}
+	if err := db.Connect(); err != nil {
+		log.Fatalf("failed to connect to database: %e", err)
+	}
+
+	publisher, err := cot.NewPublisher(db, *network, *address)
+	if err != nil {
+		log.Fatalf("failed to connect to %s: %e", *address, err)
+	}
+	defer publisher.Close()
+	publisher.Since = *since
+	publisher.Interval = *interval
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = publisher.Start(ctx)
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	<-signals
+}
@@ -0,0 +1,90 @@
+// Imports measurements from CSV files of third party DF software, and exports them for spreadsheet users
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/export"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	databaseURL = flag.String("database", os.Getenv("DATABASE"), "TimescaleDB url")
+	timezone    = flag.String("timezone", "", "time zone of the times in the file, like UTC or Europe/Amsterdam (required)")
+	timeFormat  = flag.String("time-format", time.RFC3339, "format of the times in the file, as a Go time layout")
+	columns     = flag.String("columns", "", "column names for the fields, like time=Date,bearing=DoA. Fields: time, station, latitude, longitude, bearing, quality, frequency")
+	since       = flag.Duration("since", 24*time.Hour, "export the measurements of this period")
+)
+
+func usage() {
+	_, _ = fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] import <file> | export\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if *timezone == "" || flag.NArg() < 1 {
+		usage()
+		os.Exit(1)
+	}
+
+	location, err := time.LoadLocation(*timezone)
+	if err != nil {
+		log.Fatalf("invalid time zone: %e", err)
+	}
+	format := export.NewCSV(location)
+	format.TimeFormat = *timeFormat
+	if err := format.MapColumns(*columns); err != nil {
+		log.Fatal(err)
+	}
+
+	db := database.New(*databaseURL)
+	if db == nil {
+		log.Fatal("invalid database url")
+	}
+	if err := db.Connect(); err != nil {
+		log.Fatalf("failed to connect to database: %e", err)
+	}
+
+	switch flag.Arg(0) {
+	case "import":
+		if flag.NArg() != 2 {
+			usage()
+			os.Exit(1)
+		}
+		input, err := os.Open(flag.Arg(1))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer input.Close()
+
+		measurements, errs := format.Read(input)
+		for _, err := range errs {
+			log.Printf("skipping: %s", err)
+		}
+		stored := 0
+		for _, m := range measurements {
+			if err := db.Add(m); err != nil {
+				log.Printf("failed to store measurement %v: %s", *m, err)
+				continue
+			}
+			stored++
+		}
+		log.Printf("imported %d of %d measurements", stored, len(measurements)+len(errs))
+	case "export":
+		lines, err := db.GetLines(*since)
+		if err != nil {
+			log.Fatalf("failed to get lines: %e", err)
+		}
+		if err := format.Write(os.Stdout, lines); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
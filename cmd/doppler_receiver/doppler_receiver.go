@@ -0,0 +1,85 @@
+// Runs the receiver that reads bearings from a Doppler DF unit and the position from an NMEA GPS, on serial ports
+package main
+
+import (
+	"flag"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/gpsd"
+	"github.com/hsmade/OSM-ARDF/pkg/outlier"
+	"github.com/hsmade/OSM-ARDF/pkg/position"
+	"github.com/hsmade/OSM-ARDF/pkg/receivers/doppler"
+	"github.com/hsmade/OSM-ARDF/pkg/status"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	databaseURL = flag.String("database", os.Getenv("DATABASE"), "TimescaleDB url")
+	station     = flag.String("station", "", "station name")
+	device      = flag.String("device", "/dev/ttyUSB0", "serial port of the DF unit")
+	baud        = flag.Int("baud", 9600, "baud rate of the DF unit")
+	format      = flag.String("format", "agrelo", "bearing format: "+strings.Join(doppler.Formats(), ", "))
+	pattern     = flag.String("pattern", "", "regular expression with the named groups bearing and quality, overrides the format")
+	gpsDevice   = flag.String("gps-device", "", "serial port of an NMEA GPS")
+	gpsBaud     = flag.Int("gps-baud", 4800, "baud rate of the GPS")
+	gpsdAddress = flag.String("gpsd", "", "address of a gpsd, like localhost:2947, instead of a GPS on a serial port")
+	relative    = flag.Bool("relative", false, "the antenna is on a vehicle and the bearing is relative to its course")
+	maxFixAge   = flag.Duration("max-fix-age", 5*time.Second, "don't use older GPS fixes")
+	outliers    = flag.Bool("flag-outliers", true, "flag bearings that look like reflections as suspect")
+)
+
+func main() {
+	flag.Parse()
+	if *station == "" {
+		log.Fatal("please set the station name")
+	}
+
+	var parser doppler.Format
+	var err error
+	if *pattern != "" {
+		parser, err = doppler.NewPattern(*pattern)
+	} else {
+		parser, err = doppler.Lookup(*format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db := database.New(*databaseURL)
+	if db == nil {
+		log.Fatal("invalid database url")
+	}
+	receiver := doppler.NewReceiver(db, *station, parser)
+	receiver.Relative = *relative
+	if *outliers {
+		receiver.Detector = outlier.New()
+	}
+
+	df, err := doppler.Open(*device, *baud)
+	if err != nil {
+		log.Fatalf("failed to open %s: %e", *device, err)
+	}
+	switch {
+	case *gpsdAddress != "":
+		client := gpsd.New(*gpsdAddress, *maxFixAge)
+		go client.Start()
+		receiver.Position = client
+	case *gpsDevice != "":
+		gps, err := doppler.Open(*gpsDevice, *gpsBaud)
+		if err != nil {
+			log.Fatalf("failed to open %s: %e", *gpsDevice, err)
+		}
+		provider := position.NewNMEA(*maxFixAge)
+		go func() {
+			log.Fatalf("failed to read GPS: %e", provider.Read(gps))
+		}()
+		receiver.Position = provider
+	}
+	receiver.Status = status.NewRecorder(db)
+	receiver.Status.Position = receiver.Position
+	go receiver.Status.Start()
+
+	log.Fatal(receiver.Start(df))
+}
@@ -0,0 +1,50 @@
+// Imports a recorded GPX track as the positions of a station, so bearings without a position can be placed on it
+package main
+
+import (
+	"flag"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/export"
+	"log"
+	"os"
+)
+
+var (
+	databaseURL = flag.String("database", os.Getenv("DATABASE"), "TimescaleDB url")
+	station     = flag.String("station", "", "name of the station that recorded the track")
+	file        = flag.String("file", "", "GPX file to import")
+)
+
+func main() {
+	flag.Parse()
+	if *station == "" || *file == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	input, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer input.Close()
+
+	positions, err := export.ReadGPX(input, *station)
+	if err != nil {
+		log.Fatalf("failed to read gpx: %e", err)
+	}
+
+	db := database.New(*databaseURL)
+	if db == nil {
+		log.Fatal("invalid database url")
+	}
+	if err := db.Connect(); err != nil {
+		log.Fatalf("failed to connect to database: %e", err)
+	}
+
+	for _, position := range positions {
+		if err := db.AddPosition(position); err != nil {
+			log.Fatalf("failed to store position %v: %e", position, err)
+		}
+	}
+	log.Printf("imported %d positions for %s", len(positions), *station)
+}
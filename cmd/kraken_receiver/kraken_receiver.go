@@ -0,0 +1,51 @@
+// Runs the receiver that polls the direction of arrival results of a KrakenSDR
+package main
+
+import (
+	"flag"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/gpsd"
+	"github.com/hsmade/OSM-ARDF/pkg/outlier"
+	"github.com/hsmade/OSM-ARDF/pkg/receivers/kraken"
+	"github.com/hsmade/OSM-ARDF/pkg/status"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	databaseURL = flag.String("database", os.Getenv("DATABASE"), "TimescaleDB url")
+	url         = flag.String("url", "http://localhost:8081/DOA_value.html", "url of the KrakenSDR results, in the Kraken App or JSON format")
+	station     = flag.String("station", "", "station name, defaults to the station id of the KrakenSDR")
+	relative    = flag.Bool("relative", false, "the array is mounted on a vehicle and the DoA is relative to its heading")
+	interval    = flag.Duration("interval", time.Second, "time between polls")
+	gpsdAddress = flag.String("gpsd", "", "address of a gpsd, like localhost:2947, to override the position of the KrakenSDR")
+	maxFixAge   = flag.Duration("max-fix-age", 5*time.Second, "don't use older gpsd fixes")
+	outliers    = flag.Bool("flag-outliers", true, "flag bearings that look like reflections as suspect")
+)
+
+func main() {
+	flag.Parse()
+
+	db := database.New(*databaseURL)
+	if db == nil {
+		log.Fatal("invalid database url")
+	}
+	receiver := kraken.NewReceiver(db, *url)
+	receiver.Station = *station
+	receiver.Relative = *relative
+	receiver.Interval = *interval
+	if *outliers {
+		receiver.Detector = outlier.New()
+	}
+	if *gpsdAddress != "" {
+		client := gpsd.New(*gpsdAddress, *maxFixAge)
+		go client.Start()
+		receiver.Position = client
+	}
+	receiver.Status = status.NewRecorder(db)
+	receiver.Status.Position = receiver.Position
+	go receiver.Status.Start()
+
+	log.Fatal(receiver.Start())
+}
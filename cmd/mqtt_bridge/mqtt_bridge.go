@@ -0,0 +1,81 @@
+// Runs the MQTT bridge that stores bearings from DF units and publishes the estimate and crossings
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/mqtt"
+	"github.com/hsmade/OSM-ARDF/pkg/outlier"
+	"github.com/hsmade/OSM-ARDF/pkg/smoothing"
+	"github.com/hsmade/OSM-ARDF/pkg/status"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+)
+
+var (
+	databaseURL = flag.String("database", os.Getenv("DATABASE"), "TimescaleDB url")
+	broker      = flag.String("broker", "tcp://localhost:1883", "MQTT broker url")
+	clientID    = flag.String("client-id", "osm-ardf", "MQTT client id")
+	username    = flag.String("username", os.Getenv("MQTT_USERNAME"), "MQTT username")
+	password    = flag.String("password", os.Getenv("MQTT_PASSWORD"), "MQTT password")
+	topic       = flag.String("topic", "ardf/+/bearing", "topic to receive bearings on, + is the station")
+	prefix      = flag.String("prefix", "ardf", "publish to <prefix>/estimate and <prefix>/crossings, empty to disable")
+	since       = flag.Duration("since", time.Minute, "publish the estimate and crossings of this period")
+	interval    = flag.Duration("interval", 5*time.Second, "time between publishing")
+	outliers    = flag.Bool("flag-outliers", true, "flag bearings that look like reflections as suspect")
+	smoothen    = flag.Duration("smoothing-window", 0, "smoothen the bearings of each station over this trailing window, 0 to disable")
+	smoothEvery = flag.Duration("smoothing-interval", 0, "store at most one smoothed bearing of each station per interval, 0 for one per smoothing window")
+)
+
+func main() {
+	flag.Parse()
+
+	db := database.New(*databaseURL)
+	if db == nil {
+		log.Fatal("invalid database url")
+	}
+	receiver := mqtt.NewReceiver(db, nil)
+	client, err := mqtt.Connect(*broker, *clientID, *username, *password, receiver.OnConnect)
+	if err != nil {
+		log.Fatalf("failed to connect to %s: %e", *broker, err)
+	}
+	defer client.Disconnect(250)
+
+	receiver.Client = client
+	receiver.Topic = *topic
+	if *outliers {
+		receiver.Detector = outlier.New()
+	}
+	if *smoothen > 0 {
+		receiver.Smoother = smoothing.New(*smoothen)
+		if *smoothEvery > 0 {
+			receiver.Smoother.Interval = *smoothEvery
+		}
+	}
+	receiver.Status = status.NewRecorder(db)
+	if err := receiver.Start(); err != nil {
+		log.Fatalf("failed to subscribe to %s: %e", *topic, err)
+	}
+	go receiver.Status.Start()
+	defer receiver.Status.Flush()
+	defer receiver.Stop()
+
+	if *prefix != "" {
+		publisher := mqtt.NewPublisher(db, client)
+		publisher.Prefix = *prefix
+		publisher.Since = *since
+		publisher.Interval = *interval
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			_ = publisher.Start(ctx)
+		}()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	<-signals
+}
@@ -0,0 +1,54 @@
+// Runs the server that sends the estimate as an NMEA waypoint, with navigation from a station, to chartplotters
+package main
+
+import (
+	"flag"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/nmea"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	databaseURL = flag.String("database", os.Getenv("DATABASE"), "TimescaleDB url")
+	station     = flag.String("station", "", "navigate from the position of this station, empty for only the waypoint")
+	waypoint    = flag.String("waypoint", "FOX", "name of the waypoint")
+	listen      = flag.String("listen", ":10110", "TCP address to accept clients on, empty to disable")
+	udp         = flag.String("udp", "", "comma separated UDP addresses to send to")
+	since       = flag.Duration("since", time.Minute, "use the bearings of this period")
+	interval    = flag.Duration("interval", time.Second, "time between updates")
+)
+
+func main() {
+	flag.Parse()
+
+	db := database.New(*databaseURL)
+	if db == nil {
+		log.Fatal("invalid database url")
+	}
+	if err := db.Connect(); err != nil {
+		log.Fatalf("failed to connect to database: %e", err)
+	}
+
+	server := nmea.NewServer(db, *station)
+	defer server.Close()
+	server.Waypoint = *waypoint
+	server.Since = *since
+	server.Interval = *interval
+	if *listen != "" {
+		if _, err := server.Listen(*listen); err != nil {
+			log.Fatalf("failed to listen on %s: %e", *listen, err)
+		}
+	}
+	if *udp != "" {
+		for _, address := range strings.Split(*udp, ",") {
+			if err := server.SendTo(address); err != nil {
+				log.Fatalf("failed to send to %s: %e", address, err)
+			}
+		}
+	}
+
+	server.Start()
+}
@@ -0,0 +1,114 @@
+// Replays recorded measurements into a receiver or directly into the database
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/export"
+	"github.com/hsmade/OSM-ARDF/pkg/replay"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	databaseURL = flag.String("database", os.Getenv("DATABASE"), "TimescaleDB url, used with -output database")
+	format      = flag.String("format", "jsonl", "format of the recording: jsonl, csv or gpx (an exported hunt)")
+	timezone    = flag.String("timezone", "", "time zone of the times in a csv recording, like UTC or Europe/Amsterdam")
+	timeFormat  = flag.String("time-format", time.RFC3339, "format of the times in a csv recording, as a Go time layout")
+	columns     = flag.String("columns", "", "column names for the fields of a csv recording, like time=Date,bearing=DoA")
+	speed       = flag.Float64("speed", 1, "replay speed, 1 is real time, 0 is as fast as possible")
+	rebase      = flag.Bool("rebase", true, "move the timestamps to the time of the replay")
+	output      = flag.String("output", "stdout", "where to send the measurements: stdout (for the stdin receiver) or database")
+)
+
+func usage() {
+	_, _ = fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <recording>\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 1 {
+		usage()
+		os.Exit(1)
+	}
+
+	input, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer input.Close()
+
+	measurements, err := read(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var emit func(m *types.Measurement) error
+	switch *output {
+	case "stdout":
+		emit = func(m *types.Measurement) error { return export.WriteJSONL(os.Stdout, m) }
+	case "database":
+		db := database.New(*databaseURL)
+		if db == nil {
+			log.Fatal("invalid database url")
+		}
+		if err := db.Connect(); err != nil {
+			log.Fatalf("failed to connect to database: %e", err)
+		}
+		emit = func(m *types.Measurement) error {
+			if err := db.Add(m); err != nil {
+				log.Printf("failed to store measurement %v: %s", *m, err)
+			}
+			return nil
+		}
+	default:
+		log.Fatalf("unknown output: %s", *output)
+	}
+
+	replayer := replay.New()
+	replayer.Speed = *speed
+	replayer.Rebase = *rebase
+	log.Printf("replaying %d measurements", len(measurements))
+	if err := replayer.Replay(measurements, emit); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func read(input io.Reader) ([]*types.Measurement, error) {
+	switch *format {
+	case "jsonl":
+		measurements, errs := export.ReadJSONL(input)
+		for _, err := range errs {
+			log.Printf("skipping: %s", err)
+		}
+		return measurements, nil
+	case "csv":
+		if *timezone == "" {
+			return nil, fmt.Errorf("-timezone is required for csv recordings")
+		}
+		location, err := time.LoadLocation(*timezone)
+		if err != nil {
+			return nil, err
+		}
+		csv := export.NewCSV(location)
+		csv.TimeFormat = *timeFormat
+		if err := csv.MapColumns(*columns); err != nil {
+			return nil, err
+		}
+		measurements, errs := csv.Read(input)
+		for _, err := range errs {
+			log.Printf("skipping: %s", err)
+		}
+		return measurements, nil
+	case "gpx":
+		return export.ReadGPXMeasurements(input)
+	default:
+		return nil, fmt.Errorf("unknown format: %s", *format)
+	}
+}
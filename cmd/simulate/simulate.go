@@ -0,0 +1,78 @@
+// Simulates a hunt from a scenario file and sends the measurements to a receiver or directly into the database
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/export"
+	"github.com/hsmade/OSM-ARDF/pkg/replay"
+	"github.com/hsmade/OSM-ARDF/pkg/simulator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	databaseURL = flag.String("database", os.Getenv("DATABASE"), "TimescaleDB url, used with -output database")
+	seed        = flag.Int64("seed", 0, "seed for the random errors, overrides the seed of the scenario when not 0")
+	speed       = flag.Float64("speed", 1, "simulation speed, 1 is real time, 0 is as fast as possible")
+	output      = flag.String("output", "stdout", "where to send the measurements: stdout (for the stdin receiver) or database")
+)
+
+func usage() {
+	_, _ = fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <scenario.json>\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 1 {
+		usage()
+		os.Exit(1)
+	}
+
+	scenario, err := simulator.Load(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("failed to load scenario: %e", err)
+	}
+	if *seed != 0 {
+		scenario.Seed = *seed
+	}
+	if scenario.Start.IsZero() {
+		scenario.Start = time.Now()
+	}
+
+	var emit func(m *types.Measurement) error
+	switch *output {
+	case "stdout":
+		emit = func(m *types.Measurement) error { return export.WriteJSONL(os.Stdout, m) }
+	case "database":
+		db := database.New(*databaseURL)
+		if db == nil {
+			log.Fatal("invalid database url")
+		}
+		if err := db.Connect(); err != nil {
+			log.Fatalf("failed to connect to database: %e", err)
+		}
+		emit = func(m *types.Measurement) error {
+			if err := db.Add(m); err != nil {
+				log.Printf("failed to store measurement %v: %s", *m, err)
+			}
+			return nil
+		}
+	default:
+		log.Fatalf("unknown output: %s", *output)
+	}
+
+	measurements := scenario.Measurements()
+	replayer := replay.New()
+	replayer.Speed = *speed
+	replayer.Rebase = true
+	log.Printf("simulating %d measurements with seed %d", len(measurements), scenario.Seed)
+	if err := replayer.Replay(measurements, emit); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -4,20 +4,32 @@ package main
 import (
 	"flag"
 	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/gpsd"
+	"github.com/hsmade/OSM-ARDF/pkg/outlier"
 	"github.com/hsmade/OSM-ARDF/pkg/receivers/stdin"
+	"github.com/hsmade/OSM-ARDF/pkg/smoothing"
+	"github.com/hsmade/OSM-ARDF/pkg/status"
 	"log"
 	"os"
+	"time"
 )
 
 var (
-	dbHost     = flag.String("database-host", "localhost", "TimescaleDB hostname")
-	dbPort     = flag.Uint("database-port", 5432, "TimescaleDB port")
-	dbUsername = flag.String("database-username", "postgres", "TimescaleDB username")
-	dbPassword = flag.String("database-password", "postgres", "TimescaleDB password")
-	dbDatabase = flag.String("database-name", "postgres", "TimescaleDB database name")
+	dbHost      = flag.String("database-host", "localhost", "TimescaleDB hostname")
+	dbPort      = flag.Uint("database-port", 5432, "TimescaleDB port")
+	dbUsername  = flag.String("database-username", "postgres", "TimescaleDB username")
+	dbPassword  = flag.String("database-password", "postgres", "TimescaleDB password")
+	dbDatabase  = flag.String("database-name", "postgres", "TimescaleDB database name")
+	outliers    = flag.Bool("flag-outliers", true, "flag bearings that look like reflections as suspect")
+	smoothen    = flag.Duration("smoothing-window", 0, "smoothen the bearings of each station over this trailing window, 0 to disable")
+	smoothEvery = flag.Duration("smoothing-interval", 0, "store at most one smoothed bearing of each station per interval, 0 for one per smoothing window")
+	median      = flag.Bool("smoothing-median", false, "smoothen with the circular median instead of the circular mean")
+	gpsdAddress = flag.String("gpsd", "", "address of a gpsd, like localhost:2947, to locate measurements without coordinates")
+	maxFixAge   = flag.Duration("max-fix-age", 5*time.Second, "don't use older gpsd fixes")
 )
 
 func main() {
+	flag.Parse()
 	receiver := stdin.Receiver{Database: &database.TimescaleDB{
 		Host:         *dbHost,
 		Port:         uint16(*dbPort),
@@ -25,6 +37,28 @@ func main() {
 		Password:     *dbPassword,
 		DatabaseName: *dbDatabase,
 	}}
+	if *outliers {
+		receiver.Detector = outlier.New()
+	}
+	if *smoothen > 0 {
+		receiver.Smoother = smoothing.New(*smoothen)
+		if *smoothEvery > 0 {
+			receiver.Smoother.Interval = *smoothEvery
+		}
+		receiver.Smoother.Median = *median
+	}
+	if *gpsdAddress != "" {
+		client := gpsd.New(*gpsdAddress, *maxFixAge)
+		go client.Start()
+		receiver.Position = client
+	}
+	receiver.Status = status.NewRecorder(receiver.Database)
+	receiver.Status.Position = receiver.Position
+	go receiver.Status.Start()
 
-	log.Fatal(receiver.Start(os.Stdin))
+	err := receiver.Start(os.Stdin)
+	if err := receiver.Status.Flush(); err != nil {
+		log.Printf("failed to store station status: %e", err)
+	}
+	log.Fatal(err)
 }
@@ -0,0 +1,127 @@
+// Manages the map tiles for the web server, like seeding an MBTiles file with a hunt area before going offline
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/tiles"
+	"github.com/paulmach/orb"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+)
+
+func usage() {
+	_, _ = fmt.Fprintf(flag.CommandLine.Output(), "usage: %s seed [flags] <output.mbtiles>\n", os.Args[0])
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(1)
+	}
+	switch flag.Arg(0) {
+	case "seed":
+		seed(flag.Args()[1:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
+
+func seed(args []string) {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	bbox := flags.String("bbox", "", "area to seed, as min lon,min lat,max lon,max lat")
+	polygon := flags.String("polygon", "", "area to seed, as a GeoJSON file with a polygon around the hunt")
+	minZoom := flags.Uint("min-zoom", 10, "lowest zoom level to seed")
+	maxZoom := flags.Uint("max-zoom", 16, "highest zoom level to seed")
+	upstream := flags.String("upstream", "", "tile server to fetch from, like https://tiles.example.org/{z}/{x}/{y}.png. "+
+		"Use a server that allows bulk downloads, tile.openstreetmap.org doesn't")
+	rate := flags.Float64("rate", 2, "tiles per second to fetch, 0 for no limit")
+	samples := flags.Int("samples", 5, "tiles to fetch to estimate the size, when the output has no tiles yet")
+	dryRun := flags.Bool("dry-run", false, "only count the tiles, without contacting the tile server")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "usage: %s seed [flags] <output.mbtiles>\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 || *upstream == "" || (*bbox == "") == (*polygon == "") || *minZoom > *maxZoom || *maxZoom > 30 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	var area orb.Geometry
+	var err error
+	if *bbox != "" {
+		area, err = tiles.ParseBound(*bbox)
+	} else {
+		var data []byte
+		data, err = ioutil.ReadFile(*polygon)
+		if err == nil {
+			area, err = tiles.ParseArea(data)
+		}
+	}
+	if err != nil {
+		log.Fatalf("invalid area: %v", err)
+	}
+
+	target, err := tiles.CreateMBTiles(flags.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer target.Close()
+
+	seeder := tiles.NewSeeder(tiles.NewUpstream(*upstream, ""), target)
+	seeder.Rate = *rate
+	list := tiles.Cover(area, uint8(*minZoom), uint8(*maxZoom))
+	if *dryRun {
+		*samples = 0
+	}
+	estimate, err := seeder.Estimate(list, *samples)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d tiles, %d stored, %d to fetch", estimate.Tiles, estimate.Stored, estimate.Tiles-estimate.Stored)
+	if estimate.Bytes > 0 {
+		fmt.Printf(", about %s", size(estimate.Bytes))
+	}
+	fmt.Println()
+	if *rate > 0 {
+		fmt.Printf("this takes at least %s at %g tiles per second\n", (time.Duration(float64(estimate.Tiles-estimate.Stored)/(*rate)) * time.Second).Round(time.Second), *rate)
+	}
+	if *dryRun {
+		return
+	}
+
+	var last time.Time
+	seeder.Progress = func(p tiles.Progress) {
+		done := p.Fetched + p.Skipped + p.Missing + p.Failed
+		if time.Since(last) < time.Second && done < p.Total {
+			return
+		}
+		last = time.Now()
+		fmt.Printf("\r%5.1f%% %d/%d tiles, %d fetched (%s), %d missing, %d failed",
+			100*float64(done)/float64(p.Total), done, p.Total, p.Fetched, size(p.Bytes), p.Missing, p.Failed)
+	}
+	progress, err := seeder.Seed(list)
+	fmt.Println()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if progress.Failed > 0 {
+		log.Fatalf("%d tiles failed, run the seed again to retry them", progress.Failed)
+	}
+}
+
+func size(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	}
+	return fmt.Sprintf("%.1f kB", float64(bytes)/(1<<10))
+}
@@ -3,12 +3,85 @@
 package main
 
 import (
+	"github.com/hsmade/OSM-ARDF/pkg/alerts"
+	"github.com/hsmade/OSM-ARDF/pkg/tiles"
 	"github.com/hsmade/OSM-ARDF/pkg/web"
+	"io/ioutil"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 )
 
+const defaultTileServer = "https://tile.openstreetmap.org/{z}/{x}/{y}.png"
+
 func main() {
 	s := web.NewServer(os.Getenv("DATABASE"))
+	if path := os.Getenv("STATION_TOKENS"); path != "" {
+		tokens, err := web.LoadTokens(path)
+		if err != nil {
+			log.Fatalf("failed to load station tokens: %e", err)
+		}
+		s.SetTokens(tokens)
+	}
+	s.SetMap("osm", baseMap())
+	s.SetAlerts(alertEngine())
 	log.Fatal(s.Serve(":8083"))
 }
+
+// baseMap serves the tiles from the MBTiles and PMTiles files in TILES, separated by commas,
+// then from the tile server in TILES_UPSTREAM (off to only use the files), cached in the TILES_CACHE directory
+func baseMap() tiles.Source {
+	var chain tiles.Chain
+	for _, path := range strings.Split(os.Getenv("TILES"), ",") {
+		if path == "" {
+			continue
+		}
+		source, err := tiles.Open(path)
+		if err != nil {
+			log.Fatalf("failed to open tiles: %e", err)
+		}
+		chain = append(chain, source)
+	}
+	upstream := os.Getenv("TILES_UPSTREAM")
+	if upstream == "" {
+		upstream = defaultTileServer
+	}
+	if upstream != "off" {
+		chain = append(chain, tiles.NewUpstream(upstream, os.Getenv("TILES_CACHE")))
+	}
+	return chain
+}
+
+// alertEngine alerts when a station enters an area of the GeoJSON file in ALERT_AREAS, or gets within
+// ALERT_PROXIMITY metres of the estimate (200 by default, 0 to disable). Alerts are also posted to ALERT_WEBHOOK.
+func alertEngine() *alerts.Engine {
+	engine := alerts.NewEngine(nil)
+	if path := os.Getenv("ALERT_AREAS"); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Fatalf("failed to read alert areas: %e", err)
+		}
+		geofences, err := alerts.LoadGeofences(data)
+		if err != nil {
+			log.Fatalf("failed to load alert areas: %e", err)
+		}
+		for _, geofence := range geofences {
+			engine.Rules = append(engine.Rules, geofence)
+		}
+	}
+	distance := 200.0
+	if value := os.Getenv("ALERT_PROXIMITY"); value != "" {
+		var err error
+		if distance, err = strconv.ParseFloat(value, 64); err != nil {
+			log.Fatalf("invalid alert proximity: %e", err)
+		}
+	}
+	if distance > 0 {
+		engine.Rules = append(engine.Rules, &alerts.Proximity{Distance: distance})
+	}
+	if url := os.Getenv("ALERT_WEBHOOK"); url != "" {
+		engine.Sinks = append(engine.Sinks, alerts.NewWebhook(url))
+	}
+	return engine
+}
@@ -1,3 +1,7 @@
     CREATE EXTENSION IF NOT EXISTS postgis;
-    CREATE TABLE doppler (time TIMESTAMPTZ NOT NULL DEFAULT now(), station TEXT NOT NULL, point GEOMETRY, line GEOMETRY, bearing INT);
+    CREATE TABLE doppler (time TIMESTAMPTZ NOT NULL DEFAULT now(), station TEXT NOT NULL, point GEOMETRY, line GEOMETRY, bearing INT, suspect BOOLEAN NOT NULL DEFAULT false, spread DOUBLE PRECISION NOT NULL DEFAULT 0, quality DOUBLE PRECISION NOT NULL DEFAULT 0, frequency DOUBLE PRECISION NOT NULL DEFAULT 0, vector DOUBLE PRECISION[]);
     SELECT create_hypertable('doppler', 'time', chunk_time_interval => INTERVAL '1 minute');
+    CREATE TABLE track (time TIMESTAMPTZ NOT NULL, station TEXT NOT NULL, point GEOMETRY);
+    SELECT create_hypertable('track', 'time', chunk_time_interval => INTERVAL '1 hour');
+    CREATE TABLE status (time TIMESTAMPTZ NOT NULL, station TEXT NOT NULL, last_seen TIMESTAMPTZ, messages INT NOT NULL DEFAULT 0, errors INT NOT NULL DEFAULT 0, bearing INT NOT NULL DEFAULT 0, fix_age DOUBLE PRECISION NOT NULL DEFAULT -1);
+    SELECT create_hypertable('status', 'time', chunk_time_interval => INTERVAL '1 hour');
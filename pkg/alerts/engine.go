@@ -0,0 +1,134 @@
+package alerts
+
+import (
+	"github.com/apex/log"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"sync"
+	"time"
+)
+
+// Sink receives the alerts, like the push channel of the web server or a webhook
+type Sink interface {
+	Send(alert *Alert) error
+}
+
+// SinkFunc is a function as a sink
+type SinkFunc func(alert *Alert) error
+
+func (f SinkFunc) Send(alert *Alert) error {
+	return f(alert)
+}
+
+// Engine checks the latest position of each station against the rules, and sends an alert to the sinks
+// when a station starts breaking a rule. It alerts again after the station cleared the rule.
+type Engine struct {
+	Database database.Database
+	Rules    []Rule
+	Sinks    []Sink
+	Since    time.Duration // use the positions and bearings of this period
+	Interval time.Duration // time between checks
+	mutex    sync.Mutex
+	breaking map[breakingKey]bool
+}
+
+// breakingKey is a station breaking a rule. Rules are kept by their index, as names don't have to be unique.
+type breakingKey struct {
+	rule    int
+	station string
+}
+
+func NewEngine(db database.Database, rules ...Rule) *Engine {
+	return &Engine{
+		Database: db,
+		Rules:    rules,
+		Since:    time.Minute,
+		Interval: 5 * time.Second,
+		breaking: map[breakingKey]bool{},
+	}
+}
+
+// Start checks every interval. Failing checks are logged and retried.
+func (e *Engine) Start() {
+	for {
+		if _, err := e.Check(); err != nil {
+			log.WithError(err).Error("Failed to check alert rules")
+		}
+		time.Sleep(e.Interval)
+	}
+}
+
+// Check evaluates the rules against the stored positions and the estimate from the bearings
+func (e *Engine) Check() ([]*Alert, error) {
+	positions, err := e.Database.GetPositions(e.Since)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := e.Database.GetLines(e.Since)
+	if err != nil {
+		return nil, err
+	}
+	var good []*types.Line
+	for _, line := range lines {
+		if !line.Suspect {
+			good = append(good, line)
+		}
+	}
+	estimate, err := estimator.Estimate(good)
+	if err != nil {
+		estimate = nil
+	}
+	return e.Evaluate(positions, estimate), nil
+}
+
+// Evaluate checks the latest position of each station, and sends the new alerts to the sinks.
+// The estimate may be nil when there is none.
+func (e *Engine) Evaluate(positions []*types.Position, estimate *types.Estimate) []*Alert {
+	latest := map[string]*types.Position{}
+	for _, position := range positions {
+		if position.Longitude == 0 && position.Latitude == 0 {
+			continue
+		}
+		if last := latest[position.Station]; last == nil || position.Timestamp.After(last.Timestamp) {
+			latest[position.Station] = position
+		}
+	}
+
+	e.mutex.Lock()
+	var alerts []*Alert
+	// stations without a recent position start over, so they alert again when they come back breaking a rule
+	for key := range e.breaking {
+		if latest[key.station] == nil {
+			delete(e.breaking, key)
+		}
+	}
+	for i, rule := range e.Rules {
+		for station, position := range latest {
+			key := breakingKey{rule: i, station: station}
+			alert, clear := rule.Check(position, estimate)
+			if clear {
+				delete(e.breaking, key)
+			}
+			if alert == nil || e.breaking[key] {
+				continue
+			}
+			e.breaking[key] = true
+			alert.Timestamp = position.Timestamp
+			alert.Station = station
+			alert.Longitude, alert.Latitude = position.Longitude, position.Latitude
+			alerts = append(alerts, alert)
+		}
+	}
+	e.mutex.Unlock()
+
+	for _, alert := range alerts {
+		log.WithField("alert", alert.Message).Warn("Alert")
+		for _, sink := range e.Sinks {
+			if err := sink.Send(alert); err != nil {
+				log.WithError(err).Error("Failed to send alert")
+			}
+		}
+	}
+	return alerts
+}
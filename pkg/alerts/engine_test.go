@@ -0,0 +1,142 @@
+package alerts
+
+import (
+	"encoding/json"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// track returns a position per second along the points, with 10 steps between each pair of points
+func track(station string, start time.Time, points ...[2]float64) []*types.Position {
+	var positions []*types.Position
+	for i := 0; i < len(points)-1; i++ {
+		for step := 0; step < 10; step++ {
+			fraction := float64(step) / 10
+			positions = append(positions, &types.Position{
+				Timestamp: start.Add(time.Duration(len(positions)) * time.Second),
+				Station:   station,
+				Longitude: points[i][0] + (points[i+1][0]-points[i][0])*fraction,
+				Latitude:  points[i][1] + (points[i+1][1]-points[i][1])*fraction,
+			})
+		}
+	}
+	return positions
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	start := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	geofences, _ := LoadGeofences([]byte(testAreas))
+	// car1 drives through the start corral, turns around, drives through it again and heads south
+	car1 := track("car1", start, [2]float64{4.99, 52.005}, [2]float64{5.02, 52.005}, [2]float64{4.99, 52.005}, [2]float64{4.99, 51.99})
+	// car2 drives through the corral, which doesn't apply to it, and then past the fox at 5.05, 52.0
+	car2 := track("car2", start, [2]float64{4.99, 52.005}, [2]float64{5.02, 52.005}, [2]float64{5.05, 52.005}, [2]float64{5.05, 51.99})
+	estimate := &types.Estimate{Longitude: 5.05, Latitude: 52}
+
+	var received []*Alert
+	var mutex sync.Mutex
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alert := &Alert{}
+		if err := json.NewDecoder(r.Body).Decode(alert); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mutex.Lock()
+		received = append(received, alert)
+		mutex.Unlock()
+	}))
+	defer webhook.Close()
+
+	engine := NewEngine(nil, geofences[0], &Proximity{Distance: 200})
+	var pushed []*Alert
+	engine.Sinks = []Sink{NewWebhook(webhook.URL), SinkFunc(func(alert *Alert) error {
+		pushed = append(pushed, alert)
+		return nil
+	})}
+
+	var alerts []*Alert
+	for i := range car1 {
+		// like the database, all positions so far
+		alerts = append(alerts, engine.Evaluate(append(car1[:i+1:i+1], car2[:i+1]...), estimate)...)
+	}
+
+	if len(alerts) != 3 {
+		t.Fatalf("expected 3 alerts, got %d: %v", len(alerts), alerts)
+	}
+	for i, want := range []struct {
+		station, kind string
+		at            time.Duration
+	}{
+		{"car1", KindGeofence, 4 * time.Second},
+		{"car1", KindGeofence, 14 * time.Second},
+		{"car2", KindProximity, 23 * time.Second},
+	} {
+		alert := alerts[i]
+		if alert.Station != want.station || alert.Kind != want.kind || !alert.Timestamp.Equal(start.Add(want.at)) {
+			t.Errorf("alert %d = %s %s at %s, want %s %s at %s", i, alert.Station, alert.Kind, alert.Timestamp.Sub(start), want.station, want.kind, want.at)
+		}
+	}
+	if alerts[0].Rule != "start corral" || alerts[0].Message != "car1 entered start corral" {
+		t.Errorf("unexpected alert %+v", *alerts[0])
+	}
+	if len(pushed) != 3 || len(received) != 3 || received[2].Station != "car2" || received[2].Distance > 200 {
+		t.Errorf("expected the alerts at the sinks, got %d and %d", len(pushed), len(received))
+	}
+}
+
+func TestEngine_Evaluate_SameName(t *testing.T) {
+	start := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	geofences, _ := LoadGeofences([]byte(testAreas))
+	// two areas with the same name
+	other := *geofences[1]
+	other.Title = geofences[0].Title
+	engine := NewEngine(nil, geofences[0], &other)
+
+	inside := &types.Position{Timestamp: start, Station: "car1", Longitude: 5.005, Latitude: 52.005}
+	for i := 0; i < 3; i++ {
+		inside.Timestamp = start.Add(time.Duration(i) * time.Second)
+		want := 0
+		if i == 0 {
+			want = 1
+		}
+		if alerts := engine.Evaluate([]*types.Position{inside}, nil); len(alerts) != want {
+			t.Errorf("evaluation %d gave %d alerts, want an alert at the first only", i, len(alerts))
+		}
+	}
+
+	// a station that drops out of the period starts over, and alerts when it comes back in the area
+	if alerts := engine.Evaluate(nil, nil); len(alerts) != 0 {
+		t.Errorf("unexpected alerts without positions: %v", alerts)
+	}
+	if alerts := engine.Evaluate([]*types.Position{inside}, nil); len(alerts) != 1 {
+		t.Errorf("expected an alert for the station that came back, got %v", alerts)
+	}
+}
+
+func TestEngine_Check(t *testing.T) {
+	now := time.Now()
+	// bearings from the west and the south that cross at 5.05, 52.05
+	db := &databasetest.Database{
+		Lines: []*types.Line{
+			{Position: types.Position{Timestamp: now, Station: "west", Longitude: 5, Latitude: 52.05}, Bearing: 90},
+			{Position: types.Position{Timestamp: now, Station: "south", Longitude: 5.05, Latitude: 52}, Bearing: 0},
+			{Position: types.Position{Timestamp: now, Station: "south", Longitude: 5.05, Latitude: 52}, Bearing: 270, Suspect: true},
+		},
+		Positions: []*types.Position{
+			{Timestamp: now, Station: "west", Longitude: 5, Latitude: 52.05},
+			{Timestamp: now, Station: "south", Longitude: 5.05, Latitude: 52.049},
+		},
+	}
+	engine := NewEngine(db, &Proximity{Distance: 200})
+	alerts, err := engine.Check()
+	if err != nil {
+		t.Fatalf("Check() returned error: %e", err)
+	}
+	if len(alerts) != 1 || alerts[0].Station != "south" {
+		t.Errorf("expected an alert for south, got %v", alerts)
+	}
+}
@@ -0,0 +1,140 @@
+// Package alerts warns organisers when a team enters a restricted area, or gets close to the estimated transmitter
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/kellydunn/golang-geo"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/planar"
+	"time"
+)
+
+const (
+	KindGeofence  = "geofence"
+	KindProximity = "proximity"
+
+	// rearm is how much further than the distance of a proximity rule a station has to go before it alerts again,
+	// so a jittering estimate doesn't alert over and over
+	rearm = 1.25
+)
+
+// Alert is raised when a station breaks a rule
+type Alert struct {
+	Timestamp time.Time `json:"timestamp"`
+	Rule      string    `json:"rule"`
+	Kind      string    `json:"kind"`
+	Station   string    `json:"station"`
+	Longitude float64   `json:"longitude"`
+	Latitude  float64   `json:"latitude"`
+	Distance  float64   `json:"distance,omitempty"` // to the estimate in metres, for proximity alerts
+	Message   string    `json:"message"`
+}
+
+// Rule decides whether a station breaks it. Alerts are only raised when a station starts breaking a rule.
+type Rule interface {
+	Name() string
+	// Check returns an alert when the station breaks the rule, and whether the station is clear of it
+	Check(position *types.Position, estimate *types.Estimate) (alert *Alert, clear bool)
+}
+
+// Geofence alerts when a station is inside an area, like private property or the start corral
+type Geofence struct {
+	Area     orb.Geometry // a polygon or multi polygon
+	Title    string
+	Stations []string // optional, the stations the area applies to
+}
+
+func (g *Geofence) Name() string {
+	return g.Title
+}
+
+func (g *Geofence) Check(position *types.Position, _ *types.Estimate) (*Alert, bool) {
+	if len(g.Stations) > 0 && !contains(g.Stations, position.Station) {
+		return nil, true
+	}
+	point := orb.Point{position.Longitude, position.Latitude}
+	var inside bool
+	switch area := g.Area.(type) {
+	case orb.Polygon:
+		inside = planar.PolygonContains(area, point)
+	case orb.MultiPolygon:
+		inside = planar.MultiPolygonContains(area, point)
+	}
+	if !inside {
+		return nil, true
+	}
+	return &Alert{
+		Rule:    g.Title,
+		Kind:    KindGeofence,
+		Message: fmt.Sprintf("%s entered %s", position.Station, g.Title),
+	}, false
+}
+
+// Proximity alerts when a station gets within a distance of the estimated transmitter
+type Proximity struct {
+	Distance float64 // metres
+}
+
+func (p *Proximity) Name() string {
+	return fmt.Sprintf("within %.0f m of the fox", p.Distance)
+}
+
+func (p *Proximity) Check(position *types.Position, estimate *types.Estimate) (*Alert, bool) {
+	if estimate == nil {
+		return nil, false
+	}
+	distance := geo.NewPoint(position.Latitude, position.Longitude).GreatCircleDistance(geo.NewPoint(estimate.Latitude, estimate.Longitude)) * 1000
+	if distance > p.Distance {
+		return nil, distance > p.Distance*rearm
+	}
+	return &Alert{
+		Rule:     p.Name(),
+		Kind:     KindProximity,
+		Distance: distance,
+		Message:  fmt.Sprintf("%s is %.0f m from the fox", position.Station, distance),
+	}, false
+}
+
+// LoadGeofences reads the polygons and multi polygons of a GeoJSON feature collection as geofences.
+// The name property is the name of the area, and the optional stations property lists the stations it applies to.
+func LoadGeofences(data []byte) ([]*Geofence, error) {
+	collection, err := geojson.UnmarshalFeatureCollection(data)
+	if err != nil {
+		return nil, err
+	}
+	var geofences []*Geofence
+	for i, feature := range collection.Features {
+		switch feature.Geometry.(type) {
+		case orb.Polygon, orb.MultiPolygon:
+		default:
+			return nil, fmt.Errorf("feature %d is not a polygon", i)
+		}
+		geofence := &Geofence{Area: feature.Geometry, Title: feature.Properties.MustString("name", fmt.Sprintf("area %d", i+1))}
+		if stations, ok := feature.Properties["stations"].([]interface{}); ok {
+			for _, station := range stations {
+				name, ok := station.(string)
+				if !ok {
+					return nil, fmt.Errorf("feature %d has an invalid station", i)
+				}
+				geofence.Stations = append(geofence.Stations, name)
+			}
+		}
+		geofences = append(geofences, geofence)
+	}
+	if len(geofences) == 0 {
+		return nil, errors.New("no areas found")
+	}
+	return geofences, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package alerts
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"testing"
+)
+
+const testAreas = `{"type":"FeatureCollection","features":[
+	{"type":"Feature","properties":{"name":"start corral","stations":["car1"]},"geometry":{"type":"Polygon","coordinates":[[[5.0,52.0],[5.01,52.0],[5.01,52.01],[5.0,52.01],[5.0,52.0]]]}},
+	{"type":"Feature","properties":{},"geometry":{"type":"MultiPolygon","coordinates":[[[[5.1,52.1],[5.11,52.1],[5.11,52.11],[5.1,52.1]]]]}}
+]}`
+
+func TestLoadGeofences(t *testing.T) {
+	geofences, err := LoadGeofences([]byte(testAreas))
+	if err != nil {
+		t.Fatalf("LoadGeofences() returned error: %e", err)
+	}
+	if len(geofences) != 2 || geofences[0].Name() != "start corral" || geofences[1].Name() != "area 2" {
+		t.Fatalf("unexpected geofences %v", geofences)
+	}
+	if len(geofences[0].Stations) != 1 || geofences[0].Stations[0] != "car1" || geofences[1].Stations != nil {
+		t.Errorf("unexpected stations %v, %v", geofences[0].Stations, geofences[1].Stations)
+	}
+
+	for _, invalid := range []string{
+		`{"type":"FeatureCollection","features":[]}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","properties":{},"geometry":{"type":"Point","coordinates":[5,52]}}]}`,
+		`{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"stations":[1]},"geometry":{"type":"Polygon","coordinates":[[[5,52],[5.1,52],[5,52.1],[5,52]]]}}]}`,
+		`{`,
+	} {
+		if _, err := LoadGeofences([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestGeofence_Check(t *testing.T) {
+	geofences, _ := LoadGeofences([]byte(testAreas))
+	tests := []struct {
+		geofence  int
+		station   string
+		longitude float64
+		latitude  float64
+		alert     bool
+		clearOfIt bool
+	}{
+		{0, "car1", 5.005, 52.005, true, false},
+		{0, "car2", 5.005, 52.005, false, true}, // the corral only applies to car1
+		{0, "car1", 5.02, 52.005, false, true},
+		{1, "car2", 5.108, 52.102, true, false},
+		{1, "car2", 5.102, 52.108, false, true}, // outside the triangle
+	}
+	for i, tt := range tests {
+		alert, clear := geofences[tt.geofence].Check(&types.Position{Station: tt.station, Longitude: tt.longitude, Latitude: tt.latitude}, nil)
+		if (alert != nil) != tt.alert || clear != tt.clearOfIt {
+			t.Errorf("%d: Check() = %v, %v", i, alert, clear)
+		}
+	}
+}
+
+func TestProximity_Check(t *testing.T) {
+	rule := &Proximity{Distance: 200}
+	estimate := &types.Estimate{Longitude: 5, Latitude: 52}
+	tests := []struct {
+		metres    float64
+		estimate  *types.Estimate
+		alert     bool
+		clearOfIt bool
+	}{
+		{150, estimate, true, false},
+		{220, estimate, false, false}, // close enough to not alert again
+		{300, estimate, false, true},
+		{150, nil, false, false},
+	}
+	for _, tt := range tests {
+		position := &types.Position{Station: "car1", Longitude: 5, Latitude: 52 + tt.metres/111195}
+		alert, clear := rule.Check(position, tt.estimate)
+		if (alert != nil) != tt.alert || clear != tt.clearOfIt {
+			t.Errorf("Check() at %.0f m = %v, %v", tt.metres, alert, clear)
+		}
+		if alert != nil && (alert.Kind != KindProximity || alert.Distance < 149 || alert.Distance > 151) {
+			t.Errorf("unexpected alert %+v", *alert)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Package analysis compares the recorded bearings and estimates of a hunt against the true transmitter position
+package analysis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/kellydunn/golang-geo"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// StationError holds the angular error statistics of a single station, in degrees
+type StationError struct {
+	Station           string  `json:"station"`
+	Bearings          int     `json:"bearings"`
+	MeanError         float64 `json:"mean_error"`
+	MeanAbsoluteError float64 `json:"mean_absolute_error"`
+	RMSError          float64 `json:"rms_error"`
+	MaxAbsoluteError  float64 `json:"max_absolute_error"`
+}
+
+// EstimateError is an estimate at a point in time, with its distance to the transmitter in metres
+type EstimateError struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Longitude    float64   `json:"longitude"`
+	Latitude     float64   `json:"latitude"`
+	Radius       float64   `json:"radius"`
+	Lines        int       `json:"lines"`
+	MissDistance float64   `json:"miss_distance"`
+}
+
+// Report is the result of comparing a hunt against the true transmitter position
+type Report struct {
+	Longitude      float64          `json:"longitude"`
+	Latitude       float64          `json:"latitude"`
+	FixDistance    float64          `json:"fix_distance"`
+	TimeToFirstFix *float64         `json:"time_to_first_fix"` // seconds, nil if there was no fix within FixDistance
+	Stations       []*StationError  `json:"stations"`
+	Estimates      []*EstimateError `json:"estimates"`
+}
+
+// NewReport analyses the lines against the true transmitter position.
+// For every line an estimate is made from the lines in the preceding window.
+// The first fix is the first estimate within fixDistance metres of the transmitter.
+func NewReport(lines []*types.Line, longitude, latitude float64, window time.Duration, fixDistance float64) *Report {
+	sorted := make([]*types.Line, len(lines))
+	copy(sorted, lines)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	report := Report{
+		Longitude:   longitude,
+		Latitude:    latitude,
+		FixDistance: fixDistance,
+		Stations:    stationErrors(sorted, longitude, latitude),
+		Estimates:   []*EstimateError{},
+	}
+
+	transmitter := geo.NewPoint(latitude, longitude)
+	for i, line := range sorted {
+		if i+1 < len(sorted) && sorted[i+1].Timestamp.Equal(line.Timestamp) {
+			continue // only estimate once all lines of this moment are in
+		}
+		estimate, err := estimator.Estimate(estimator.Window(sorted, line.Timestamp, window))
+		if err != nil {
+			continue
+		}
+		missDistance := transmitter.GreatCircleDistance(geo.NewPoint(estimate.Latitude, estimate.Longitude)) * 1000
+		report.Estimates = append(report.Estimates, &EstimateError{
+			Timestamp:    estimate.Timestamp,
+			Longitude:    estimate.Longitude,
+			Latitude:     estimate.Latitude,
+			Radius:       estimate.Radius,
+			Lines:        estimate.Lines,
+			MissDistance: missDistance,
+		})
+		if report.TimeToFirstFix == nil && missDistance <= fixDistance {
+			seconds := estimate.Timestamp.Sub(sorted[0].Timestamp).Seconds()
+			report.TimeToFirstFix = &seconds
+		}
+	}
+	return &report
+}
+
+func stationErrors(lines []*types.Line, longitude, latitude float64) []*StationError {
+	transmitter := geo.NewPoint(latitude, longitude)
+	stations := map[string]*StationError{}
+	var names []string
+	for _, line := range lines {
+		station, ok := stations[line.Station]
+		if !ok {
+			station = &StationError{Station: line.Station}
+			stations[line.Station] = station
+			names = append(names, line.Station)
+		}
+		want := geo.NewPoint(line.Latitude, line.Longitude).BearingTo(transmitter)
+		angularError := AngleDifference(float64(line.Bearing), want)
+		station.Bearings++
+		station.MeanError += angularError
+		station.MeanAbsoluteError += math.Abs(angularError)
+		station.RMSError += angularError * angularError
+		station.MaxAbsoluteError = math.Max(station.MaxAbsoluteError, math.Abs(angularError))
+	}
+
+	sort.Strings(names)
+	result := []*StationError{}
+	for _, name := range names {
+		station := stations[name]
+		station.MeanError /= float64(station.Bearings)
+		station.MeanAbsoluteError /= float64(station.Bearings)
+		station.RMSError = math.Sqrt(station.RMSError / float64(station.Bearings))
+		result = append(result, station)
+	}
+	return result
+}
+
+// AngleDifference returns a - b in degrees, normalised to [-180, 180)
+func AngleDifference(a, b float64) float64 {
+	return math.Mod(math.Mod(a-b+180, 360)+360, 360) - 180
+}
+
+// WriteJSON writes the full report as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteStationsCSV writes the angular error per station as CSV
+func (r *Report) WriteStationsCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"station", "bearings", "mean_error", "mean_absolute_error", "rms_error", "max_absolute_error"})
+	for _, station := range r.Stations {
+		_ = writer.Write([]string{
+			station.Station,
+			strconv.Itoa(station.Bearings),
+			formatFloat(station.MeanError),
+			formatFloat(station.MeanAbsoluteError),
+			formatFloat(station.RMSError),
+			formatFloat(station.MaxAbsoluteError),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteEstimatesCSV writes the miss distance of the estimates over time as CSV
+func (r *Report) WriteEstimatesCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"timestamp", "longitude", "latitude", "radius", "lines", "miss_distance"})
+	for _, estimate := range r.Estimates {
+		_ = writer.Write([]string{
+			estimate.Timestamp.Format(time.RFC3339),
+			strconv.FormatFloat(estimate.Longitude, 'f', 6, 64),
+			strconv.FormatFloat(estimate.Latitude, 'f', 6, 64),
+			formatFloat(estimate.Radius),
+			strconv.Itoa(estimate.Lines),
+			formatFloat(estimate.MissDistance),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
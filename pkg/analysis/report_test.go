@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"bytes"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAngleDifference(t *testing.T) {
+	tests := []struct {
+		a, b float64
+		want float64
+	}{
+		{10, 5, 5},
+		{5, 10, -5},
+		{359, 1, -2},
+		{1, 359, 2},
+		{180, 0, -180},
+	}
+	for _, tt := range tests {
+		if got := AngleDifference(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("AngleDifference(%f, %f) = %f, want %f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func testLines(start time.Time) []*types.Line {
+	return []*types.Line{
+		{Position: types.Position{Timestamp: start, Station: "south", Longitude: 5, Latitude: 51.99}, Bearing: 10},
+		{Position: types.Position{Timestamp: start.Add(time.Minute), Station: "west", Longitude: 4.98, Latitude: 52}, Bearing: 90},
+		{Position: types.Position{Timestamp: start.Add(2 * time.Minute), Station: "south", Longitude: 5, Latitude: 51.99}, Bearing: 0},
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	start := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	report := NewReport(testLines(start), 5, 52, 90*time.Second, 50)
+
+	if len(report.Stations) != 2 {
+		t.Fatalf("expected 2 stations, got %d", len(report.Stations))
+	}
+	south := report.Stations[0]
+	if south.Station != "south" || south.Bearings != 2 || math.Abs(south.MeanError-5) > 0.1 || math.Abs(south.MaxAbsoluteError-10) > 0.1 {
+		t.Errorf("unexpected statistics for station south: %+v", south)
+	}
+	west := report.Stations[1]
+	if west.Station != "west" || west.Bearings != 1 || west.MeanAbsoluteError > 0.2 {
+		t.Errorf("unexpected statistics for station west: %+v", west)
+	}
+
+	if len(report.Estimates) != 2 {
+		t.Fatalf("expected 2 estimates, got %d", len(report.Estimates))
+	}
+	if report.Estimates[0].MissDistance < 100 {
+		t.Errorf("expected the first estimate to miss, got %+v", report.Estimates[0])
+	}
+	if report.Estimates[1].MissDistance > 50 {
+		t.Errorf("expected the second estimate to be a fix, got %+v", report.Estimates[1])
+	}
+	if report.TimeToFirstFix == nil || *report.TimeToFirstFix != 120 {
+		t.Errorf("expected a time to first fix of 120 seconds, got %v", report.TimeToFirstFix)
+	}
+}
+
+func TestNewReport_NoFix(t *testing.T) {
+	start := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	report := NewReport(testLines(start)[:2], 5, 52, time.Hour, 50)
+	if report.TimeToFirstFix != nil {
+		t.Errorf("expected no fix, got %f", *report.TimeToFirstFix)
+	}
+}
+
+func TestReport_WriteCSV(t *testing.T) {
+	start := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	report := NewReport(testLines(start), 5, 52, 90*time.Second, 50)
+
+	var stations bytes.Buffer
+	if err := report.WriteStationsCSV(&stations); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(stations.String()), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[1], "south,2,") {
+		t.Errorf("unexpected stations csv: %s", stations.String())
+	}
+
+	var estimates bytes.Buffer
+	if err := report.WriteEstimatesCSV(&estimates); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(estimates.String()), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[1], "2019-10-01T12:01:00Z,") {
+		t.Errorf("unexpected estimates csv: %s", estimates.String())
+	}
+}
@@ -0,0 +1,109 @@
+package aprs
+
+import (
+	"github.com/apex/log"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/kellydunn/golang-geo"
+	"sync"
+	"time"
+)
+
+// Beacon sends the estimated transmitter position as an APRS object
+type Beacon struct {
+	Database    database.Database
+	Sender      Sender
+	Callsign    string
+	Path        []string
+	Name        string        // object name
+	Since       time.Duration // estimate from the bearings of this period
+	Interval    time.Duration // time between estimates
+	MinInterval time.Duration // minimal time between beacons
+	MaxInterval time.Duration // beacon at least this often, even when the estimate didn't move
+	MinDistance float64       // metres the estimate must move before beaconing within MaxInterval
+	mutex       sync.Mutex
+	last        *types.Estimate
+	lastSent    time.Time
+	now         func() time.Time
+}
+
+func NewBeacon(db database.Database, sender Sender, callsign string) *Beacon {
+	return &Beacon{
+		Database:    db,
+		Sender:      sender,
+		Callsign:    callsign,
+		Path:        []string{"WIDE2-1"},
+		Name:        "FOX",
+		Since:       5 * time.Minute,
+		Interval:    30 * time.Second,
+		MinInterval: time.Minute,
+		MaxInterval: 10 * time.Minute,
+		MinDistance: 100,
+		now:         time.Now,
+	}
+}
+
+// Start estimates and beacons every interval, until sending fails
+func (b *Beacon) Start() error {
+	for {
+		lines, err := b.Database.GetLines(b.Since)
+		if err != nil {
+			log.WithError(err).Error("failed to get lines")
+		} else if err := b.Update(lines); err != nil {
+			return err
+		}
+		time.Sleep(b.Interval)
+	}
+}
+
+// Update estimates from the lines, and sends the estimate when the rate limits allow it
+func (b *Beacon) Update(lines []*types.Line) error {
+	var good []*types.Line
+	for _, line := range lines {
+		if !line.Suspect {
+			good = append(good, line)
+		}
+	}
+	estimate, err := estimator.Estimate(good)
+	if err != nil {
+		log.WithError(err).Debug("no estimate to beacon")
+		return nil
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !b.due(estimate) {
+		return nil
+	}
+
+	packet := &Packet{
+		Source:      b.Callsign,
+		Destination: Destination,
+		Path:        b.Path,
+		Info:        Object(b.Name, estimate),
+	}
+	log.Infof("beaconing %s", packet)
+	if err := b.Sender.Send(packet); err != nil {
+		return err
+	}
+	b.last = estimate
+	b.lastSent = b.now()
+	return nil
+}
+
+// due returns whether the estimate should be sent now
+func (b *Beacon) due(estimate *types.Estimate) bool {
+	if b.last == nil {
+		return true
+	}
+	elapsed := b.now().Sub(b.lastSent)
+	if elapsed < b.MinInterval {
+		return false
+	}
+	if elapsed >= b.MaxInterval {
+		return true
+	}
+	moved := geo.NewPoint(b.last.Latitude, b.last.Longitude).GreatCircleDistance(geo.NewPoint(estimate.Latitude, estimate.Longitude)) * 1000
+	return moved >= b.MinDistance
+}
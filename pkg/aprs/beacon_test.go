@@ -0,0 +1,66 @@
+package aprs
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"testing"
+	"time"
+)
+
+type senderMock struct {
+	packets []*Packet
+}
+
+func (s *senderMock) Send(p *Packet) error {
+	s.packets = append(s.packets, p)
+	return nil
+}
+
+func (s *senderMock) Close() error {
+	return nil
+}
+
+// linesTo returns two perpendicular lines that cross at 5, latitude
+func linesTo(latitude float64) []*types.Line {
+	return []*types.Line{
+		{Position: types.Position{Station: "south", Longitude: 5, Latitude: latitude - 0.01}, Bearing: 0},
+		{Position: types.Position{Station: "west", Longitude: 4.98, Latitude: latitude}, Bearing: 90},
+	}
+}
+
+func TestBeacon_Update(t *testing.T) {
+	sender := &senderMock{}
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	beacon := NewBeacon(nil, sender, "PD0ABC")
+	beacon.now = func() time.Time { return now }
+
+	steps := []struct {
+		elapsed  time.Duration
+		latitude float64
+		wantSent int
+	}{
+		{0, 52, 1},                  // first estimate
+		{30 * time.Second, 52.1, 1}, // too soon
+		{2 * time.Minute, 52.1, 2},  // moved
+		{4 * time.Minute, 52.1, 2},  // didn't move
+		{13 * time.Minute, 52.1, 3}, // max interval passed
+		{20 * time.Minute, 52.1, 3}, // no estimate possible
+	}
+	for i, step := range steps {
+		now = now.Add(step.elapsed)
+		lines := linesTo(step.latitude)
+		if i == len(steps)-1 {
+			lines = lines[:1]
+		}
+		if err := beacon.Update(lines); err != nil {
+			t.Fatalf("step %d: Update() returned error: %e", i, err)
+		}
+		if len(sender.packets) != step.wantSent {
+			t.Errorf("step %d: sent %d packets, want %d", i, len(sender.packets), step.wantSent)
+		}
+	}
+
+	packet := sender.packets[0]
+	if packet.Source != "PD0ABC" || packet.Destination != Destination || packet.Info[:11] != ";FOX      *" {
+		t.Errorf("unexpected packet: %s", packet)
+	}
+}
@@ -0,0 +1,92 @@
+// Package aprs formats and sends APRS packets, through APRS-IS or a KISS TNC
+package aprs
+
+import (
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"strings"
+	"time"
+)
+
+// Destination is the experimental tocall that identifies OSM-ARDF
+const Destination = "APZARD"
+
+type Packet struct {
+	Source      string
+	Destination string
+	Path        []string
+	Info        string
+}
+
+// String returns the packet in the TNC2 text format that APRS-IS uses
+func (p *Packet) String() string {
+	header := p.Source + ">" + p.Destination
+	if len(p.Path) > 0 {
+		header += "," + strings.Join(p.Path, ",")
+	}
+	return header + ":" + p.Info
+}
+
+// Object returns the info field of an APRS object report for the estimate, shown with the DF symbol.
+// The name is cut or padded to the 9 characters an object name has.
+func Object(name string, estimate *types.Estimate) string {
+	if len(name) > 9 {
+		name = name[:9]
+	}
+	return fmt.Sprintf(";%-9s*%s%s/%s\\%s",
+		name,
+		estimate.Timestamp.UTC().Format("021504z"),
+		latitude(estimate.Latitude),
+		longitude(estimate.Longitude),
+		fmt.Sprintf("+-%.0fm from %d bearings", estimate.Radius, estimate.Lines),
+	)
+}
+
+// KillObject returns the info field that removes the object from the maps
+func KillObject(name string, at time.Time) string {
+	if len(name) > 9 {
+		name = name[:9]
+	}
+	return fmt.Sprintf(";%-9s_%s", name, at.UTC().Format("021504z"))
+}
+
+// latitude formats as ddmm.hhN
+func latitude(value float64) string {
+	hemisphere := "N"
+	if value < 0 {
+		hemisphere = "S"
+	}
+	degrees, minutes := degreesMinutes(value)
+	return fmt.Sprintf("%02d%05.2f%s", degrees, minutes, hemisphere)
+}
+
+// longitude formats as dddmm.hhE
+func longitude(value float64) string {
+	hemisphere := "E"
+	if value < 0 {
+		hemisphere = "W"
+	}
+	degrees, minutes := degreesMinutes(value)
+	return fmt.Sprintf("%03d%05.2f%s", degrees, minutes, hemisphere)
+}
+
+func degreesMinutes(value float64) (int, float64) {
+	// round to hundredths of minutes first, so 59.999 doesn't become 60.00
+	hundredths := math.Round(math.Abs(value) * 60 * 100)
+	degrees := int(hundredths / 6000)
+	return degrees, (hundredths - float64(degrees)*6000) / 100
+}
+
+// Passcode returns the APRS-IS passcode for the callsign
+func Passcode(callsign string) int {
+	call := strings.ToUpper(strings.SplitN(callsign, "-", 2)[0])
+	hash := 0x73e2
+	for i := 0; i < len(call); i += 2 {
+		hash ^= int(call[i]) << 8
+		if i+1 < len(call) {
+			hash ^= int(call[i+1])
+		}
+	}
+	return hash & 0x7fff
+}
@@ -0,0 +1,70 @@
+package aprs
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"testing"
+	"time"
+)
+
+func TestObject(t *testing.T) {
+	estimate := &types.Estimate{
+		Timestamp: time.Date(2019, 10, 9, 23, 45, 10, 0, time.UTC),
+		Longitude: -72.02916667,
+		Latitude:  49.05833333,
+		Radius:    123.4,
+		Lines:     12,
+	}
+	tests := []struct {
+		name       string
+		objectName string
+		want       string
+	}{
+		{"short name", "FOX", ";FOX      *092345z4903.50N/07201.75W\\+-123m from 12 bearings"},
+		{"long name", "FOXHUNT-2019", ";FOXHUNT-2*092345z4903.50N/07201.75W\\+-123m from 12 bearings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Object(tt.objectName, estimate); got != tt.want {
+				t.Errorf("Object() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKillObject(t *testing.T) {
+	want := ";FOX      _092345z"
+	if got := KillObject("FOX", time.Date(2019, 10, 9, 23, 45, 0, 0, time.UTC)); got != want {
+		t.Errorf("KillObject() = %s, want %s", got, want)
+	}
+}
+
+func TestLatitudeLongitude(t *testing.T) {
+	if got := latitude(-33.99999999); got != "3400.00S" {
+		t.Errorf("latitude() = %s, want 3400.00S", got)
+	}
+	if got := longitude(5.1669); got != "00510.01E" {
+		t.Errorf("longitude() = %s, want 00510.01E", got)
+	}
+}
+
+func TestPacket_String(t *testing.T) {
+	p := &Packet{Source: "PD0ABC-9", Destination: Destination, Path: []string{"WIDE1-1", "WIDE2-1"}, Info: ">test"}
+	if got := p.String(); got != "PD0ABC-9>APZARD,WIDE1-1,WIDE2-1:>test" {
+		t.Errorf("String() = %s", got)
+	}
+}
+
+func TestPasscode(t *testing.T) {
+	tests := []struct {
+		callsign string
+		want     int
+	}{
+		{"N0CALL", 13023},
+		{"n0call-9", 13023},
+	}
+	for _, tt := range tests {
+		if got := Passcode(tt.callsign); got != tt.want {
+			t.Errorf("Passcode(%s) = %d, want %d", tt.callsign, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package aprs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Sender interface {
+	Send(p *Packet) error
+	Close() error
+}
+
+// ISSender sends packets to an APRS-IS server
+type ISSender struct {
+	conn net.Conn
+}
+
+// NewISSender connects and logs in to an APRS-IS server, like rotate.aprs2.net:14580
+func NewISSender(address, callsign string, passcode int) (*ISSender, error) {
+	conn, err := net.DialTimeout("tcp", address, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	_, err = fmt.Fprintf(conn, "user %s pass %d vers OSM-ARDF 1.0\r\n", callsign, passcode)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// the server answers with comment lines, of which one is the login result
+	_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		log.Debugf("APRS-IS: %s", strings.TrimSpace(line))
+		if strings.HasPrefix(line, "# logresp") {
+			if strings.Contains(line, "unverified") {
+				conn.Close()
+				return nil, errors.New("APRS-IS did not accept the passcode")
+			}
+			break
+		}
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	return &ISSender{conn: conn}, nil
+}
+
+func (s *ISSender) Send(p *Packet) error {
+	_, err := fmt.Fprintf(s.conn, "%s\r\n", p.String())
+	return err
+}
+
+func (s *ISSender) Close() error {
+	return s.conn.Close()
+}
+
+const (
+	fend  = 0xc0
+	fesc  = 0xdb
+	tfend = 0xdc
+	tfesc = 0xdd
+)
+
+// KISSSender sends packets to a TNC that speaks KISS over TCP, like Direwolf on port 8001
+type KISSSender struct {
+	conn net.Conn
+}
+
+func NewKISSSender(address string) (*KISSSender, error) {
+	conn, err := net.DialTimeout("tcp", address, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &KISSSender{conn: conn}, nil
+}
+
+func (s *KISSSender) Send(p *Packet) error {
+	frame, err := encodeAX25(p)
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.Write(kissFrame(frame))
+	return err
+}
+
+func (s *KISSSender) Close() error {
+	return s.conn.Close()
+}
+
+// kissFrame wraps an AX.25 frame as a KISS data frame for port 0
+func kissFrame(frame []byte) []byte {
+	result := []byte{fend, 0x00}
+	for _, b := range frame {
+		switch b {
+		case fend:
+			result = append(result, fesc, tfend)
+		case fesc:
+			result = append(result, fesc, tfesc)
+		default:
+			result = append(result, b)
+		}
+	}
+	return append(result, fend)
+}
+
+// encodeAX25 returns the packet as an AX.25 UI frame, without flags and checksum
+func encodeAX25(p *Packet) ([]byte, error) {
+	addresses := append([]string{p.Destination, p.Source}, p.Path...)
+	var frame []byte
+	for i, address := range addresses {
+		encoded, err := encodeAddress(address, i == len(addresses)-1)
+		if err != nil {
+			return nil, err
+		}
+		frame = append(frame, encoded...)
+	}
+	frame = append(frame, 0x03, 0xf0) // UI frame, no layer 3
+	return append(frame, []byte(p.Info)...), nil
+}
+
+// encodeAddress returns a callsign with optional SSID as the 7 bytes of an AX.25 address
+func encodeAddress(address string, last bool) ([]byte, error) {
+	repeated := strings.HasSuffix(address, "*")
+	address = strings.TrimSuffix(address, "*")
+	parts := strings.SplitN(strings.ToUpper(address), "-", 2)
+	call := parts[0]
+	if call == "" || len(call) > 6 {
+		return nil, errors.New("invalid callsign: " + address)
+	}
+	ssid := 0
+	if len(parts) == 2 {
+		var err error
+		ssid, err = strconv.Atoi(parts[1])
+		if err != nil || ssid < 0 || ssid > 15 {
+			return nil, errors.New("invalid SSID: " + address)
+		}
+	}
+
+	result := make([]byte, 7)
+	for i := 0; i < 6; i++ {
+		c := byte(' ')
+		if i < len(call) {
+			c = call[i]
+		}
+		result[i] = c << 1
+	}
+	result[6] = 0x60 | byte(ssid)<<1
+	if repeated {
+		result[6] |= 0x80
+	}
+	if last {
+		result[6] |= 0x01
+	}
+	return result, nil
+}
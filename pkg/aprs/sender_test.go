@@ -0,0 +1,106 @@
+package aprs
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServer accepts a single connection and hands it to handle
+func fakeServer(t *testing.T, handle func(conn net.Conn)) (string, <-chan struct{}) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		handle(conn)
+	}()
+	return listener.Addr().String(), done
+}
+
+func TestISSender(t *testing.T) {
+	var login, packet string
+	address, done := fakeServer(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		_, _ = conn.Write([]byte("# aprsc 2.1.4\r\n"))
+		login, _ = reader.ReadString('\n')
+		_, _ = conn.Write([]byte("# logresp N0CALL verified, server T2TEST\r\n"))
+		packet, _ = reader.ReadString('\n')
+	})
+
+	sender, err := NewISSender(address, "N0CALL", 13023)
+	if err != nil {
+		t.Fatalf("NewISSender() returned error: %e", err)
+	}
+	err = sender.Send(&Packet{Source: "N0CALL", Destination: Destination, Path: []string{"TCPIP*"}, Info: ";FOX      *092345z4903.50N/07201.75W\\"})
+	if err != nil {
+		t.Fatalf("Send() returned error: %e", err)
+	}
+	_ = sender.Close()
+	<-done
+
+	if login != "user N0CALL pass 13023 vers OSM-ARDF 1.0\r\n" {
+		t.Errorf("unexpected login: %q", login)
+	}
+	if packet != "N0CALL>APZARD,TCPIP*:;FOX      *092345z4903.50N/07201.75W\\\r\n" {
+		t.Errorf("unexpected packet: %q", packet)
+	}
+}
+
+func TestISSender_Unverified(t *testing.T) {
+	address, done := fakeServer(t, func(conn net.Conn) {
+		_, _ = bufio.NewReader(conn).ReadString('\n')
+		_, _ = conn.Write([]byte("# logresp N0CALL unverified, server T2TEST\r\n"))
+	})
+	if _, err := NewISSender(address, "N0CALL", 1); err == nil {
+		t.Errorf("expected an error for a wrong passcode")
+	}
+	<-done
+}
+
+func TestKISSSender(t *testing.T) {
+	var frame []byte
+	address, done := fakeServer(t, func(conn net.Conn) {
+		frame, _ = ioutil.ReadAll(conn)
+	})
+
+	sender, err := NewKISSSender(address)
+	if err != nil {
+		t.Fatalf("NewKISSSender() returned error: %e", err)
+	}
+	if err := sender.Send(&Packet{Source: "PD0ABC-9", Destination: Destination, Path: []string{"WIDE2-1"}, Info: "\xc0"}); err != nil {
+		t.Fatalf("Send() returned error: %e", err)
+	}
+	_ = sender.Close()
+	<-done
+
+	want := []byte{0xc0, 0x00,
+		'A' << 1, 'P' << 1, 'Z' << 1, 'A' << 1, 'R' << 1, 'D' << 1, 0x60,
+		'P' << 1, 'D' << 1, '0' << 1, 'A' << 1, 'B' << 1, 'C' << 1, 0x60 | 9<<1,
+		'W' << 1, 'I' << 1, 'D' << 1, 'E' << 1, '2' << 1, ' ' << 1, 0x60 | 1<<1 | 0x01,
+		0x03, 0xf0, 0xdb, 0xdc, 0xc0}
+	if !bytes.Equal(frame, want) {
+		t.Errorf("unexpected frame:\n%x\nwant\n%x", frame, want)
+	}
+}
+
+func TestEncodeAddress_Invalid(t *testing.T) {
+	for _, address := range []string{"", "TOOLONGCALL", "PD0ABC-16", "PD0ABC-x"} {
+		if _, err := encodeAddress(address, false); err == nil || !strings.Contains(err.Error(), "invalid") {
+			t.Errorf("expected an error for %q, got %v", address, err)
+		}
+	}
+}
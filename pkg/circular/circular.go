@@ -0,0 +1,75 @@
+// Package circular has statistics for angles in degrees, where 359 and 1 are 2 degrees apart
+package circular
+
+import (
+	"math"
+	"sort"
+)
+
+// Difference returns a - b, normalised to [-180, 180]
+func Difference(a, b float64) float64 {
+	return math.Remainder(a-b, 360)
+}
+
+// Normalise returns the angle in [0, 360)
+func Normalise(angle float64) float64 {
+	angle = math.Mod(angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	return angle
+}
+
+// Mean returns the circular mean of the angles, and the circular standard deviation in degrees
+func Mean(angles []float64) (mean float64, spread float64) {
+	if len(angles) == 0 {
+		return 0, 0
+	}
+	var sin, cos float64
+	for _, angle := range angles {
+		sin += math.Sin(angle * math.Pi / 180)
+		cos += math.Cos(angle * math.Pi / 180)
+	}
+	sin /= float64(len(angles))
+	cos /= float64(len(angles))
+	length := math.Min(math.Hypot(sin, cos), 1)
+	if length == 0 {
+		return 0, 180
+	}
+	return Normalise(math.Atan2(sin, cos) * 180 / math.Pi), math.Sqrt(-2*math.Log(length)) * 180 / math.Pi
+}
+
+// Median returns the angle out of angles that has the smallest total distance to all other angles
+func Median(angles []float64) float64 {
+	median, best := 0.0, math.Inf(1)
+	for _, candidate := range angles {
+		var total float64
+		for _, angle := range angles {
+			total += math.Abs(Difference(angle, candidate))
+		}
+		if total < best {
+			median, best = candidate, total
+		}
+	}
+	return Normalise(median)
+}
+
+// MedianDeviation returns the median absolute difference of the angles with the median, in degrees.
+// It is scaled to match the standard deviation of normally distributed angles, like the spread of Mean,
+// but a few outliers hardly change it.
+func MedianDeviation(angles []float64, median float64) float64 {
+	if len(angles) == 0 {
+		return 0
+	}
+	var deviations []float64
+	for _, angle := range angles {
+		deviations = append(deviations, math.Abs(Difference(angle, median)))
+	}
+	sort.Float64s(deviations)
+	middle := len(deviations) / 2
+	deviation := deviations[middle]
+	if len(deviations)%2 == 0 {
+		deviation = (deviations[middle-1] + deviations[middle]) / 2
+	}
+	return deviation * 1.4826
+}
@@ -0,0 +1,84 @@
+package circular
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDifference(t *testing.T) {
+	tests := []struct {
+		a, b float64
+		want float64
+	}{
+		{10, 5, 5},
+		{5, 10, -5},
+		{359, 1, -2},
+		{1, 359, 2},
+	}
+	for _, tt := range tests {
+		if got := Difference(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Difference(%f, %f) = %f, want %f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMean(t *testing.T) {
+	tests := []struct {
+		name       string
+		angles     []float64
+		wantMean   float64
+		wantSpread float64
+	}{
+		{"empty", nil, 0, 0},
+		{"single", []float64{42}, 42, 0},
+		{"around north", []float64{350, 10}, 0, 10.02},
+		{"around south", []float64{170, 190, 180}, 180, 8.18},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, spread := Mean(tt.angles)
+			if math.Abs(Difference(mean, tt.wantMean)) > 1e-6 || math.Abs(spread-tt.wantSpread) > 0.01 {
+				t.Errorf("Mean() = %f, %f, want %f, %f", mean, spread, tt.wantMean, tt.wantSpread)
+			}
+		})
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		angles []float64
+		want   float64
+	}{
+		{"single", []float64{42}, 42},
+		{"outlier", []float64{358, 2, 0, 1, 180}, 1},
+		{"negative", []float64{-10}, 350},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Median(tt.angles); got != tt.want {
+				t.Errorf("Median() = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMedianDeviation(t *testing.T) {
+	tests := []struct {
+		name   string
+		angles []float64
+		median float64
+		want   float64
+	}{
+		{"empty", nil, 0, 0},
+		{"around north", []float64{358, 2, 0, 1, 359}, 0, 1 * 1.4826},
+		{"outlier", []float64{358, 2, 0, 1, 359, 180}, 0, 1.5 * 1.4826},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MedianDeviation(tt.angles, tt.median); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("MedianDeviation() = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package cluster groups crossings that lie close together into candidate transmitter sites
+package cluster
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"sort"
+	"time"
+)
+
+const noise = -1
+
+// cell is a square of the grid that indexes the crossings
+type cell struct {
+	x, y int
+}
+
+// Crossings clusters the crossings with DBSCAN. Crossings within distance metres of each other are neighbours,
+// and a cluster needs at least minPoints neighbouring crossings to start. Crossings that don't belong to any
+// cluster are dropped.
+// Each cluster is returned as a single crossing at the weighted centre, with the summed weight of its members.
+func Crossings(crossings []*types.Crossing, distance float64, minPoints int) []*types.Crossing {
+	if len(crossings) == 0 {
+		return nil
+	}
+
+	// work in metres around the first crossing
+	x := make([]float64, len(crossings))
+	y := make([]float64, len(crossings))
+	for i, crossing := range crossings {
+		x[i], y[i] = estimator.Project(crossings[0].Longitude, crossings[0].Latitude, crossing.Longitude, crossing.Latitude)
+	}
+
+	// index the crossings on a grid of distance sized cells, so only the surrounding cells are searched for neighbours
+	size := distance
+	if size <= 0 {
+		size = 1
+	}
+	cellOf := func(i int) cell {
+		return cell{int(math.Floor(x[i] / size)), int(math.Floor(y[i] / size))}
+	}
+	grid := map[cell][]int{}
+	for i := range crossings {
+		grid[cellOf(i)] = append(grid[cellOf(i)], i)
+	}
+
+	neighbours := func(i int) []int {
+		var result []int
+		centre := cellOf(i)
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				for _, j := range grid[cell{centre.x + dx, centre.y + dy}] {
+					if math.Hypot(x[i]-x[j], y[i]-y[j]) <= distance {
+						result = append(result, j)
+					}
+				}
+			}
+		}
+		sort.Ints(result)
+		return result
+	}
+
+	labels := make([]int, len(crossings))
+	visited := make([]bool, len(crossings))
+	clusters := 0
+	for i := range crossings {
+		if visited[i] {
+			continue
+		}
+		visited[i] = true
+		seeds := neighbours(i)
+		if weight(crossings, seeds) < minPoints {
+			labels[i] = noise
+			continue
+		}
+
+		clusters++
+		labels[i] = clusters
+		for len(seeds) > 0 {
+			j := seeds[0]
+			seeds = seeds[1:]
+			if labels[j] == noise {
+				labels[j] = clusters
+			}
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			labels[j] = clusters
+			if more := neighbours(j); weight(crossings, more) >= minPoints {
+				seeds = append(seeds, more...)
+			}
+		}
+	}
+
+	members := make([][]int, clusters)
+	for i, label := range labels {
+		if label != noise {
+			members[label-1] = append(members[label-1], i)
+		}
+	}
+
+	var result []*types.Crossing
+	for _, member := range members {
+		result = append(result, merge(crossings, member, x, y))
+	}
+	return result
+}
+
+// weight returns the total weight of the crossings at the given indexes
+func weight(crossings []*types.Crossing, indexes []int) (total int) {
+	for _, i := range indexes {
+		total += crossingWeight(crossings[i])
+	}
+	return
+}
+
+// crossingWeight returns the weight of a single crossing, which is at least 1
+func crossingWeight(crossing *types.Crossing) int {
+	if crossing.Weight < 1 {
+		return 1
+	}
+	return crossing.Weight
+}
+
+func merge(crossings []*types.Crossing, members []int, x, y []float64) *types.Crossing {
+	var centreX, centreY, angle, dilution float64
+	var newest time.Time
+	total := weight(crossings, members)
+	stations := map[string]bool{}
+	distances := map[string]float64{}
+	distanceCounts := map[string]int{}
+	for _, i := range members {
+		w := float64(crossingWeight(crossings[i]))
+		centreX += x[i] * w
+		centreY += y[i] * w
+		angle += crossings[i].Angle * w
+		dilution += crossings[i].Dilution * w
+		if crossings[i].Timestamp.After(newest) {
+			newest = crossings[i].Timestamp
+		}
+		for _, station := range crossings[i].Stations {
+			stations[station] = true
+		}
+		for station, distance := range crossings[i].Distances {
+			distances[station] += distance
+			distanceCounts[station]++
+		}
+	}
+	centreX /= float64(total)
+	centreY /= float64(total)
+	for station := range distances {
+		distances[station] /= float64(distanceCounts[station])
+	}
+
+	var spread float64
+	for _, i := range members {
+		dx, dy := x[i]-centreX, y[i]-centreY
+		spread += (dx*dx + dy*dy) * float64(crossingWeight(crossings[i]))
+	}
+
+	result := types.Crossing{
+		Timestamp: newest,
+		Weight:    total,
+		Spread:    math.Sqrt(spread / float64(total)),
+		Angle:     angle / float64(total),
+		Dilution:  dilution / float64(total),
+	}
+	if len(distances) > 0 {
+		result.Distances = distances
+	}
+	result.Longitude, result.Latitude = estimator.Unproject(crossings[0].Longitude, crossings[0].Latitude, centreX, centreY)
+	for station := range stations {
+		result.Stations = append(result.Stations, station)
+	}
+	sort.Strings(result.Stations)
+	return &result
+}
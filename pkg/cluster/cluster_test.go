@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// crossingAt returns a crossing at x, y metres from 5, 52
+func crossingAt(x, y float64, stations ...string) *types.Crossing {
+	longitude, latitude := estimator.Unproject(5, 52, x, y)
+	return &types.Crossing{Longitude: longitude, Latitude: latitude, Weight: 1, Stations: stations}
+}
+
+func TestCrossings(t *testing.T) {
+	crossings := []*types.Crossing{
+		crossingAt(0, 0, "a", "b"),
+		crossingAt(30, 0, "a", "c"),
+		crossingAt(60, 0, "b", "c"),
+		crossingAt(5000, 5000, "a", "b"),
+		crossingAt(5000, 5040, "b", "d"),
+		crossingAt(-5000, 0, "c", "d"),
+	}
+
+	tests := []struct {
+		name      string
+		minPoints int
+		want      []*types.Crossing
+	}{
+		{
+			name:      "keep single crossings",
+			minPoints: 1,
+			want: []*types.Crossing{
+				{Weight: 3, Stations: []string{"a", "b", "c"}, Spread: math.Sqrt(600)},
+				{Weight: 2, Stations: []string{"a", "b", "d"}, Spread: 20},
+				{Weight: 1, Stations: []string{"c", "d"}},
+			},
+		},
+		{
+			name:      "drop noise",
+			minPoints: 3,
+			want: []*types.Crossing{
+				{Weight: 3, Stations: []string{"a", "b", "c"}, Spread: math.Sqrt(600)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Crossings(crossings, 50, tt.minPoints)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Crossings() returned %d clusters, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i].Weight != want.Weight || !reflect.DeepEqual(got[i].Stations, want.Stations) || math.Abs(got[i].Spread-want.Spread) > 0.1 {
+					t.Errorf("cluster %d = %+v, want %+v", i, got[i], want)
+				}
+			}
+
+			x, y := estimator.Project(5, 52, got[0].Longitude, got[0].Latitude)
+			if math.Abs(x-30) > 0.1 || math.Abs(y) > 0.1 {
+				t.Errorf("expected the first cluster at 30, 0, got %f, %f", x, y)
+			}
+		})
+	}
+}
+
+func TestCrossings_Empty(t *testing.T) {
+	if got := Crossings(nil, 50, 1); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
+
+func TestCrossings_Chain(t *testing.T) {
+	// a chain of crossings 40 metres apart crosses many cells of the grid, diagonally and around the origin
+	start := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	var crossings []*types.Crossing
+	for i := -50; i < 50; i++ {
+		crossing := crossingAt(float64(i)*28, float64(i)*28)
+		crossing.Timestamp = start.Add(time.Duration(i) * time.Second)
+		crossings = append(crossings, crossing)
+	}
+	crossings = append(crossings, crossingAt(2000, -2000))
+	got := Crossings(crossings, 40, 1)
+	if len(got) != 2 || got[0].Weight != 100 || got[1].Weight != 1 {
+		t.Fatalf("expected the chain and a single crossing, got %+v", got)
+	}
+	if !got[0].Timestamp.Equal(start.Add(49 * time.Second)) {
+		t.Errorf("expected the time of the newest crossing, got %s", got[0].Timestamp)
+	}
+}
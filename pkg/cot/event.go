@@ -0,0 +1,112 @@
+// Package cot publishes stations, bearings and the transmitter estimate as Cursor-on-Target events for ATAK and WinTAK
+package cot
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/kellydunn/golang-geo"
+	"time"
+)
+
+const (
+	TypeStation  = "a-f-G-U-C" // friendly ground unit
+	TypeBearing  = "u-rb-a"    // range and bearing line
+	TypeEstimate = "a-u-G"     // unknown ground track
+
+	// unknown height and error, as defined by the CoT schema
+	unknown = 9999999.0
+)
+
+type Event struct {
+	XMLName xml.Name `xml:"event"`
+	Version string   `xml:"version,attr"`
+	UID     string   `xml:"uid,attr"`
+	Type    string   `xml:"type,attr"`
+	How     string   `xml:"how,attr"`
+	Time    string   `xml:"time,attr"`
+	Start   string   `xml:"start,attr"`
+	Stale   string   `xml:"stale,attr"`
+	Point   Point    `xml:"point"`
+	Detail  Detail   `xml:"detail"`
+}
+
+type Point struct {
+	Latitude  float64 `xml:"lat,attr"`
+	Longitude float64 `xml:"lon,attr"`
+	Height    float64 `xml:"hae,attr"`
+	CE        float64 `xml:"ce,attr"` // circular error in metres
+	LE        float64 `xml:"le,attr"` // linear (height) error in metres
+}
+
+type Detail struct {
+	Contact      *Contact `xml:"contact,omitempty"`
+	Remarks      string   `xml:"remarks,omitempty"`
+	Range        *Value   `xml:"range,omitempty"`
+	Bearing      *Value   `xml:"bearing,omitempty"`
+	Inclination  *Value   `xml:"inclination,omitempty"`
+	RangeUnits   *Value   `xml:"rangeUnits,omitempty"`
+	BearingUnits *Value   `xml:"bearingUnits,omitempty"`
+	NorthRef     *Value   `xml:"northRef,omitempty"`
+	StrokeColor  *Value   `xml:"strokeColor,omitempty"`
+}
+
+type Contact struct {
+	Callsign string `xml:"callsign,attr"`
+}
+
+type Value struct {
+	Value string `xml:"value,attr"`
+}
+
+func newEvent(uid, eventType string, at time.Time, stale time.Duration, longitude, latitude float64) *Event {
+	return &Event{
+		Version: "2.0",
+		UID:     uid,
+		Type:    eventType,
+		How:     "m-g",
+		Time:    cotTime(time.Now()),
+		Start:   cotTime(at),
+		Stale:   cotTime(time.Now().Add(stale)),
+		Point:   Point{Latitude: latitude, Longitude: longitude, Height: unknown, CE: unknown, LE: unknown},
+	}
+}
+
+// StationEvent returns the position of a station
+func StationEvent(position *types.Position, stale time.Duration) *Event {
+	event := newEvent("osm-ardf-station-"+position.Station, TypeStation, position.Timestamp, stale, position.Longitude, position.Latitude)
+	event.Detail.Contact = &Contact{Callsign: position.Station}
+	return event
+}
+
+// BearingEvent returns a bearing of a station as a range and bearing line
+func BearingEvent(line *types.Line, stale time.Duration) *Event {
+	length := geo.NewPoint(line.Latitude, line.Longitude).GreatCircleDistance(geo.NewPoint(line.LatitudeEnd, line.LongitudeEnd)) * 1000
+	uid := fmt.Sprintf("osm-ardf-bearing-%s-%d", line.Station, line.Timestamp.UnixNano())
+	event := newEvent(uid, TypeBearing, line.Timestamp, stale, line.Longitude, line.Latitude)
+	event.Detail.Contact = &Contact{Callsign: fmt.Sprintf("%s %d°", line.Station, line.Bearing)}
+	event.Detail.Range = &Value{fmt.Sprintf("%.0f", length)}
+	event.Detail.Bearing = &Value{fmt.Sprintf("%d", line.Bearing)}
+	event.Detail.Inclination = &Value{"0"}
+	event.Detail.RangeUnits = &Value{"1"}   // range is in metres, shown in kilometres
+	event.Detail.BearingUnits = &Value{"0"} // degrees
+	event.Detail.NorthRef = &Value{"0"}     // true north
+	if line.Suspect {
+		event.Detail.Remarks = "suspect bearing"
+		event.Detail.StrokeColor = &Value{"-30720"} // orange, as signed ARGB
+	}
+	return event
+}
+
+// EstimateEvent returns the estimated transmitter position, with its uncertainty as circular error
+func EstimateEvent(estimate *types.Estimate, stale time.Duration) *Event {
+	event := newEvent("osm-ardf-estimate", TypeEstimate, estimate.Timestamp, stale, estimate.Longitude, estimate.Latitude)
+	event.Point.CE = estimate.Radius
+	event.Detail.Contact = &Contact{Callsign: "fox"}
+	event.Detail.Remarks = fmt.Sprintf("estimated from %d bearings", estimate.Lines)
+	return event
+}
+
+func cotTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
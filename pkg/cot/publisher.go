@@ -0,0 +1,141 @@
+package cot
+
+import (
+	"context"
+	"encoding/xml"
+	"github.com/apex/log"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"net"
+	"time"
+)
+
+type Publisher struct {
+	Database      database.Database
+	Since         time.Duration // publish the stations and bearings of this period
+	Interval      time.Duration // time between publishing rounds
+	StationStale  time.Duration
+	BearingStale  time.Duration
+	EstimateStale time.Duration
+	network       string
+	address       string
+	conn          net.Conn // nil after a failed write, until the next send connects again
+}
+
+// NewPublisher returns a publisher that sends to a UDP (multicast) or TCP address, like udp 239.2.3.1:6969
+func NewPublisher(db database.Database, network, address string) (*Publisher, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{
+		Database:      db,
+		Since:         time.Minute,
+		Interval:      5 * time.Second,
+		StationStale:  2 * time.Minute,
+		BearingStale:  time.Minute,
+		EstimateStale: time.Minute,
+		network:       network,
+		address:       address,
+		conn:          conn,
+	}, nil
+}
+
+// Start publishes every interval until the context is cancelled. Failures are logged, and the next round tries again.
+func (p *Publisher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Publish(); err != nil {
+			log.WithError(err).Warn("Failed to publish events")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Publish sends the latest position of every station, the recent bearings and the estimate
+func (p *Publisher) Publish() error {
+	positions, err := p.Database.GetPositions(p.Since)
+	if err != nil {
+		return err
+	}
+	lines, err := p.Database.GetLines(p.Since)
+	if err != nil {
+		return err
+	}
+
+	var events []*Event
+	for _, position := range latestPositions(positions) {
+		events = append(events, StationEvent(position, p.StationStale))
+	}
+	var good []*types.Line
+	for _, line := range lines {
+		events = append(events, BearingEvent(line, p.BearingStale))
+		if !line.Suspect {
+			good = append(good, line)
+		}
+	}
+	if estimate, err := estimator.Estimate(good); err == nil {
+		events = append(events, EstimateEvent(estimate, p.EstimateStale))
+	}
+
+	for _, event := range events {
+		if err := p.send(event); err != nil {
+			return err
+		}
+	}
+	log.Debugf("published %d events", len(events))
+	return nil
+}
+
+// send writes a single event. For UDP this is a single datagram.
+// The connection is dropped when writing fails, and made again for the next event, so TAK servers can restart.
+func (p *Publisher) send(event *Event) error {
+	data, err := xml.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if p.conn == nil {
+		if p.conn, err = net.Dial(p.network, p.address); err != nil {
+			return err
+		}
+	}
+	if _, err = p.conn.Write(append([]byte(xml.Header), data...)); err != nil {
+		_ = p.conn.Close()
+		p.conn = nil
+	}
+	return err
+}
+
+// Close closes the connection
+func (p *Publisher) Close() error {
+	if p.conn == nil {
+		return nil
+	}
+	return p.conn.Close()
+}
+
+// latestPositions returns the last position of each station
+func latestPositions(positions []*types.Position) []*types.Position {
+	latest := map[string]*types.Position{}
+	var stations []string
+	for _, position := range positions {
+		current, ok := latest[position.Station]
+		if !ok {
+			stations = append(stations, position.Station)
+		}
+		if !ok || position.Timestamp.After(current.Timestamp) {
+			latest[position.Station] = position
+		}
+	}
+	var result []*types.Position
+	for _, station := range stations {
+		result = append(result, latest[station])
+	}
+	return result
+}
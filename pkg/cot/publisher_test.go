@@ -0,0 +1,141 @@
+package cot
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestPublisher_Publish(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	now := time.Now()
+	db := &databasetest.Database{
+		Positions: []*types.Position{
+			{Timestamp: now.Add(-time.Second), Station: "south", Longitude: 5, Latitude: 51.98},
+			{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99},
+			{Timestamp: now, Station: "west", Longitude: 4.98, Latitude: 52},
+		},
+		Lines: []*types.Line{
+			{Position: types.Position{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99}, LongitudeEnd: 5, LatitudeEnd: 52.2, Bearing: 0},
+			{Position: types.Position{Timestamp: now, Station: "west", Longitude: 4.98, Latitude: 52}, LongitudeEnd: 5.3, LatitudeEnd: 52, Bearing: 90},
+		},
+	}
+	publisher, err := NewPublisher(db, "udp", listener.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer publisher.Close()
+
+	if err := publisher.Publish(); err != nil {
+		t.Fatalf("Publish() returned error: %e", err)
+	}
+
+	var events []Event
+	buffer := make([]byte, 65536)
+	for i := 0; i < 5; i++ {
+		_ = listener.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := listener.ReadFrom(buffer)
+		if err != nil {
+			t.Fatalf("failed to read event %d: %e", i, err)
+		}
+		event := Event{}
+		if err := xml.Unmarshal(buffer[:n], &event); err != nil {
+			t.Fatalf("failed to parse event %s: %e", buffer[:n], err)
+		}
+		events = append(events, event)
+	}
+
+	want := []struct {
+		uid, eventType string
+	}{
+		{"osm-ardf-station-south", TypeStation},
+		{"osm-ardf-station-west", TypeStation},
+		{"", TypeBearing},
+		{"", TypeBearing},
+		{"osm-ardf-estimate", TypeEstimate},
+	}
+	for i, w := range want {
+		if events[i].Type != w.eventType || (w.uid != "" && events[i].UID != w.uid) {
+			t.Errorf("event %d = %s %s, want %s %s", i, events[i].UID, events[i].Type, w.uid, w.eventType)
+		}
+	}
+	if events[0].Point.Latitude != 51.99 {
+		t.Errorf("expected the latest position of the station, got %+v", events[0].Point)
+	}
+	if events[2].Detail.Bearing.Value != "0" || events[2].Detail.Range.Value == "0" {
+		t.Errorf("unexpected bearing event: %+v", events[2].Detail)
+	}
+	if events[4].Point.CE > 100 || events[4].Point.Latitude < 51.99 {
+		t.Errorf("unexpected estimate: %+v", events[4].Point)
+	}
+	stale, err := time.Parse("2006-01-02T15:04:05.000Z", events[4].Stale)
+	if err != nil || stale.Before(now) {
+		t.Errorf("expected a stale time in the future, got %s", events[4].Stale)
+	}
+}
+
+func TestPublisher_Start(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	now := time.Now()
+	db := &databasetest.Database{
+		Positions:    []*types.Position{{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99}},
+		LineFailures: 2,
+	}
+	publisher, err := NewPublisher(db, "tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer publisher.Close()
+	publisher.Interval = 10 * time.Millisecond
+
+	// the TAK server drops the first connection
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- publisher.Start(ctx)
+	}()
+
+	// the publisher keeps going after the database failures and connects again
+	_ = listener.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	conn, err = listener.Accept()
+	if err != nil {
+		t.Fatalf("publisher didn't connect again: %e", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('>')
+	if err != nil || line != "<?xml version=\"1.0\" encoding=\"UTF-8\"?>" {
+		t.Errorf("unexpected start of an event %q: %v", line, err)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Start() returned %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start() didn't return after the context was cancelled")
+	}
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"errors"
 	"github.com/hsmade/OSM-ARDF/pkg/types"
 	"time"
 )
@@ -8,7 +9,22 @@ import (
 type Database interface {
 	Connect() error
 	Add(m *types.Measurement) error
+	AddPosition(p *types.Position) error
+	AddStatus(s *types.Status) error
 	GetPositions(since time.Duration) ([]*types.Position, error)
 	GetLines(since time.Duration) ([]*types.Line, error)
 	GetCrossings(since time.Duration) ([]*types.Crossing, error)
+	GetStatus(since time.Duration) ([]*types.Status, error)
+}
+
+// Validate checks a measurement before it is stored
+func Validate(m *types.Measurement) error {
+	if m.Bearing > 360 || m.Bearing < 0 {
+		return errors.New("bearing must be 0 - 360")
+	}
+
+	if m.Station == "" {
+		return errors.New("missing station name")
+	}
+	return nil
 }
@@ -0,0 +1,100 @@
+// Package databasetest provides an in-memory database for tests
+package databasetest
+
+import (
+	"errors"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"sync"
+	"time"
+)
+
+var _ database.Database = &Database{}
+
+// Database keeps what is added to it, and returns the positions, lines, crossings and reports it was set up with.
+// It is safe for concurrent use, once it is set up
+type Database struct {
+	Positions    []*types.Position
+	Lines        []*types.Line
+	Crossings    []*types.Crossing
+	Statuses     []*types.Status                  // AddStatus appends to these
+	ConnectErr   error                            // returned by Connect
+	Reject       func(m *types.Measurement) error // optional, when it returns an error Add fails with it
+	LineFailures int                              // the amount of calls to GetLines that fail
+
+	mutex        sync.Mutex
+	measurements []*types.Measurement
+	lineQueries  int
+}
+
+func (d *Database) Connect() error { return d.ConnectErr }
+
+func (d *Database) Add(m *types.Measurement) error {
+	if d.Reject != nil {
+		if err := d.Reject(m); err != nil {
+			return err
+		}
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.measurements = append(d.measurements, m)
+	return nil
+}
+
+func (d *Database) AddPosition(p *types.Position) error { return nil }
+
+func (d *Database) AddStatus(s *types.Status) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.Statuses = append(d.Statuses, s)
+	return nil
+}
+
+func (d *Database) GetPositions(since time.Duration) ([]*types.Position, error) {
+	return d.Positions, nil
+}
+
+func (d *Database) GetLines(since time.Duration) ([]*types.Line, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.lineQueries++
+	if d.LineFailures > 0 {
+		d.LineFailures--
+		return nil, errors.New("database is down")
+	}
+	return d.Lines, nil
+}
+
+func (d *Database) GetCrossings(since time.Duration) ([]*types.Crossing, error) {
+	return d.Crossings, nil
+}
+
+func (d *Database) GetStatus(since time.Duration) ([]*types.Status, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.Statuses, nil
+}
+
+// Measurements returns the measurements that were added, in order
+func (d *Database) Measurements() []*types.Measurement {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return append([]*types.Measurement{}, d.measurements...)
+}
+
+// Last returns the last measurement that was added, or nil when there wasn't any
+func (d *Database) Last() *types.Measurement {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if len(d.measurements) == 0 {
+		return nil
+	}
+	return d.measurements[len(d.measurements)-1]
+}
+
+// LineQueries returns how often GetLines was called
+func (d *Database) LineQueries() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.lineQueries
+}
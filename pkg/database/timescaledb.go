@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/apex/log"
+	"github.com/hsmade/OSM-ARDF/pkg/quality"
 	"github.com/hsmade/OSM-ARDF/pkg/types"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/kellydunn/golang-geo"
@@ -63,12 +64,8 @@ func (d *TimescaleDB) Connect() error {
 }
 
 func (d *TimescaleDB) Add(m *types.Measurement) error {
-	if m.Bearing > 360 || m.Bearing < 0 {
-		return errors.New("bearing must be 0 - 360")
-	}
-
-	if m.Station == "" {
-		return errors.New("missing station name")
+	if err := Validate(m); err != nil {
+		return err
 	}
 
 	if d.connectionPool == nil {
@@ -81,10 +78,21 @@ func (d *TimescaleDB) Add(m *types.Measurement) error {
 
 	defer conn.Release()
 
+	if m.Longitude == 0 && m.Latitude == 0 {
+		position, err := d.locate(conn, m.Station, m.Timestamp)
+		if err != nil {
+			return err
+		}
+		if position != nil {
+			log.Debugf("located measurement of %s at %v", m.Station, position)
+			m.Longitude, m.Latitude = position.Longitude, position.Latitude
+		}
+	}
+
 	startPoint := geo.NewPoint(m.Latitude, m.Longitude)
 	endPoint := startPoint.PointAtDistanceAndBearing(25, float64(m.Bearing))
 
-	query := "insert into \"doppler\"(time, station, point, line, bearing) values($1, $2, ST_GeomFromWKB($3), ST_GeomFromWKB($4), $5)"
+	query := "insert into \"doppler\"(time, station, point, line, bearing, suspect, spread, quality, frequency, vector) values($1, $2, ST_GeomFromWKB($3), ST_GeomFromWKB($4), $5, $6, $7, $8, $9, $10)"
 	log.Debugf("insert query: %s", query)
 	result, err := conn.Exec(context.Background(), query,
 		m.Timestamp,
@@ -92,6 +100,46 @@ func (d *TimescaleDB) Add(m *types.Measurement) error {
 		wkb.Value(orb.Point{m.Longitude, m.Latitude}),
 		wkb.Value(orb.LineString{orb.Point{m.Longitude, m.Latitude}, orb.Point{endPoint.Lng(), endPoint.Lat()}}),
 		m.Bearing,
+		m.Suspect,
+		m.Spread,
+		m.Quality,
+		m.Frequency,
+		m.Vector,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() != 1 {
+		return errors.New(fmt.Sprintf("insert resulted in %d amount of rows, instead of 1", result.RowsAffected()))
+	}
+	return nil
+}
+
+// AddPosition stores a position of a station without a bearing, like a recorded GPS track.
+// Measurements that come in without a position are placed on this track.
+func (d *TimescaleDB) AddPosition(p *types.Position) error {
+	if p.Station == "" {
+		return errors.New("missing station name")
+	}
+
+	if d.connectionPool == nil {
+		return errors.New("please connect to the database first")
+	}
+	conn, err := d.connectionPool.Acquire(context.Background())
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	query := "insert into \"track\"(time, station, point) values($1, $2, ST_GeomFromWKB($3))"
+	log.Debugf("insert query: %s", query)
+	result, err := conn.Exec(context.Background(), query,
+		p.Timestamp,
+		p.Station,
+		wkb.Value(orb.Point{p.Longitude, p.Latitude}),
 	)
 
 	if err != nil {
@@ -104,6 +152,112 @@ func (d *TimescaleDB) Add(m *types.Measurement) error {
 	return nil
 }
 
+// AddStatus stores a report of a receiver about a station
+func (d *TimescaleDB) AddStatus(s *types.Status) error {
+	if s.Station == "" {
+		return errors.New("missing station name")
+	}
+
+	if d.connectionPool == nil {
+		return errors.New("please connect to the database first")
+	}
+	conn, err := d.connectionPool.Acquire(context.Background())
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	var lastSeen *time.Time
+	if !s.LastSeen.IsZero() {
+		lastSeen = &s.LastSeen
+	}
+	query := "insert into \"status\"(time, station, last_seen, messages, errors, bearing, fix_age) values($1, $2, $3, $4, $5, $6, $7)"
+	log.Debugf("insert query: %s", query)
+	result, err := conn.Exec(context.Background(), query,
+		s.Timestamp,
+		s.Station,
+		lastSeen,
+		s.Messages,
+		s.Errors,
+		s.Bearing,
+		s.FixAge,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() != 1 {
+		return errors.New(fmt.Sprintf("insert resulted in %d amount of rows, instead of 1", result.RowsAffected()))
+	}
+	return nil
+}
+
+// locate returns the position of the station at the given time on its recorded track, or nil if it is unknown
+func (d *TimescaleDB) locate(conn *pgxpool.Conn, station string, at time.Time) (*types.Position, error) {
+	query := "(select time, ST_AsBinary(point) from track where station = $1 and time <= $2 order by time desc limit 1) " +
+		"union all (select time, ST_AsBinary(point) from track where station = $1 and time > $2 order by time asc limit 1)"
+	log.Debugf("locate query: %s", query)
+	rows, err := conn.Query(context.Background(), query, station, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var before, after *types.Position
+	for rows.Next() {
+		var (
+			datetime time.Time
+			point    orb.Point
+		)
+		if err := rows.Scan(&datetime, wkb.Scanner(&point)); err != nil {
+			return nil, err
+		}
+		position := &types.Position{Timestamp: datetime, Station: station, Longitude: point.X(), Latitude: point.Y()}
+		if datetime.After(at) {
+			after = position
+		} else {
+			before = position
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return interpolate(before, after, at), nil
+}
+
+// maxTrackGap is the maximal time between a measurement and the track points it is placed between
+const maxTrackGap = 5 * time.Minute
+
+// interpolate returns the position between before and after at the given time.
+// Track points that are more than maxTrackGap away are not used.
+func interpolate(before, after *types.Position, at time.Time) *types.Position {
+	if before != nil && at.Sub(before.Timestamp) > maxTrackGap {
+		before = nil
+	}
+	if after != nil && after.Timestamp.Sub(at) > maxTrackGap {
+		after = nil
+	}
+
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		return after
+	case after == nil || !after.Timestamp.After(before.Timestamp):
+		return before
+	}
+
+	fraction := at.Sub(before.Timestamp).Seconds() / after.Timestamp.Sub(before.Timestamp).Seconds()
+	return &types.Position{
+		Timestamp: at,
+		Station:   before.Station,
+		Longitude: before.Longitude + (after.Longitude-before.Longitude)*fraction,
+		Latitude:  before.Latitude + (after.Latitude-before.Latitude)*fraction,
+	}
+}
+
 func (d *TimescaleDB) GetPositions(since time.Duration) (positions []*types.Position, err error) {
 	if since.Seconds() < 1 {
 		return nil, errors.New("since should be >= 1")
@@ -120,7 +274,8 @@ func (d *TimescaleDB) GetPositions(since time.Duration) (positions []*types.Posi
 	defer conn.Release()
 
 	// get average / center point
-	query := fmt.Sprintf("select time, station, ST_AsBinary(point) from doppler where time > NOW() - interval '%d seconds'", int(since.Seconds()))
+	query := fmt.Sprintf("select time, station, ST_AsBinary(point) from doppler where time > NOW() - interval '%d seconds' "+
+		"union all select time, station, ST_AsBinary(point) from track where time > NOW() - interval '%d seconds'", int(since.Seconds()), int(since.Seconds()))
 	log.Debugf("get positions query: %s", query)
 	rows, err := conn.Query(context.Background(), query)
 
@@ -171,7 +326,7 @@ func (d *TimescaleDB) GetLines(since time.Duration) (lines []*types.Line, err er
 
 	defer conn.Release()
 
-	query := fmt.Sprintf("select time, station, ST_AsBinary(line) from doppler where time > NOW() - interval '%d seconds'", int(since.Seconds()))
+	query := fmt.Sprintf("select time, station, ST_AsBinary(line), bearing, suspect, spread, quality, frequency, vector from doppler where time > NOW() - interval '%d seconds'", int(since.Seconds()))
 	log.Debugf("get lines query: %s", query)
 	rows, err := conn.Query(context.Background(), query)
 
@@ -183,12 +338,18 @@ func (d *TimescaleDB) GetLines(since time.Duration) (lines []*types.Line, err er
 
 	for rows.Next() {
 		var (
-			datetime time.Time
-			station  string
-			line     orb.LineString
+			datetime  time.Time
+			station   string
+			line      orb.LineString
+			bearing   int
+			suspect   bool
+			spread    float64
+			quality   float64
+			frequency float64
+			vector    []float64
 		)
 
-		err := rows.Scan(&datetime, &station, wkb.Scanner(&line))
+		err := rows.Scan(&datetime, &station, wkb.Scanner(&line), &bearing, &suspect, &spread, &quality, &frequency, &vector)
 		if err != nil {
 			log.Errorf("failed to get row: %e", err)
 			return nil, err
@@ -202,6 +363,12 @@ func (d *TimescaleDB) GetLines(since time.Duration) (lines []*types.Line, err er
 			},
 			LongitudeEnd: line[1].X(),
 			LatitudeEnd:  line[1].Y(),
+			Bearing:      bearing,
+			Suspect:      suspect,
+			Spread:       spread,
+			Quality:      quality,
+			Frequency:    frequency,
+			Vector:       vector,
 		}
 		lines = append(lines, &newLine)
 		log.Debugf("got line: %v", newLine)
@@ -225,9 +392,9 @@ func (d *TimescaleDB) GetCrossings(since time.Duration) (crossings []*types.Cros
 
 	defer conn.Release()
 
-	// INTERSECTION:
-	query := fmt.Sprintf("select ST_AsBinary(ST_Intersection(a.line, b.line)), COUNT(a.station) FROM doppler AS a, doppler AS b WHERE ST_Intersects(a.line, b.line) AND a.station < b.station AND a.time > NOW() - interval '%d seconds' AND b.time > NOW() - interval '%d seconds' GROUP BY ST_Intersection(a.line, b.line);", int(since.Seconds()), int(since.Seconds()))
-	log.Debugf("get lines query: %s", query)
+	// every intersection of the lines of two different stations
+	query := fmt.Sprintf("select GREATEST(a.time, b.time), ST_AsBinary(ST_Intersection(a.line, b.line)), a.station, ST_AsBinary(a.point), a.bearing, b.station, ST_AsBinary(b.point), b.bearing FROM doppler AS a, doppler AS b WHERE ST_Intersects(a.line, b.line) AND GeometryType(ST_Intersection(a.line, b.line)) = 'POINT' AND a.station < b.station AND a.time > NOW() - interval '%d seconds' AND b.time > NOW() - interval '%d seconds';", int(since.Seconds()), int(since.Seconds()))
+	log.Debugf("get crossings query: %s", query)
 	rows, err := conn.Query(context.Background(), query)
 
 	if err != nil {
@@ -238,22 +405,79 @@ func (d *TimescaleDB) GetCrossings(since time.Duration) (crossings []*types.Cros
 
 	for rows.Next() {
 		var (
-			crossing     orb.Point
-			weight       int
+			datetime time.Time
+			crossing orb.Point
+			a        types.Line
+			b        types.Line
+			pointA   orb.Point
+			pointB   orb.Point
 		)
 
-		err := rows.Scan(wkb.Scanner(&crossing), &weight)
+		err := rows.Scan(&datetime, wkb.Scanner(&crossing), &a.Station, wkb.Scanner(&pointA), &a.Bearing, &b.Station, wkb.Scanner(&pointB), &b.Bearing)
 		if err != nil {
 			log.Errorf("failed to get row: %e", err)
 			return nil, err
 		}
+		a.Longitude, a.Latitude = pointA.X(), pointA.Y()
+		b.Longitude, b.Latitude = pointB.X(), pointB.Y()
 		newCrossing := types.Crossing{
+			Timestamp: datetime,
 			Longitude: crossing.X(),
-			Latitude: crossing.Y(),
-			Weight:weight,
+			Latitude:  crossing.Y(),
+			Weight:    1,
+			Stations:  []string{a.Station, b.Station},
+		}
+		if err := quality.Assess(&newCrossing, &a, &b); err != nil {
+			log.Debugf("dropping crossing: %e", err)
+			continue
 		}
 		crossings = append(crossings, &newCrossing)
-		log.Debugf("got line: %v", newCrossing)
+		log.Debugf("got crossing: %v", newCrossing)
+	}
+	err = rows.Err()
+	return
+}
+
+// GetStatus returns the reports about the stations of the last period, sorted by time
+func (d *TimescaleDB) GetStatus(since time.Duration) (statuses []*types.Status, err error) {
+	if since.Seconds() < 1 {
+		return nil, errors.New("since should be >= 1")
+	}
+	if d.connectionPool == nil {
+		return nil, errors.New("please connect to the database first")
+	}
+	conn, err := d.connectionPool.Acquire(context.Background())
+	if err != nil {
+		log.Errorf("failed to get connection from database pool: %e", err)
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	query := fmt.Sprintf("select time, station, last_seen, messages, errors, bearing, fix_age from status "+
+		"where time > NOW() - interval '%d seconds' order by time", int(since.Seconds()))
+	log.Debugf("get status query: %s", query)
+	rows, err := conn.Query(context.Background(), query)
+	if err != nil {
+		log.Errorf("failed to run query: %e", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			status   types.Status
+			lastSeen *time.Time
+		)
+		err := rows.Scan(&status.Timestamp, &status.Station, &lastSeen, &status.Messages, &status.Errors, &status.Bearing, &status.FixAge)
+		if err != nil {
+			log.Errorf("failed to get row: %e", err)
+			return nil, err
+		}
+		if lastSeen != nil {
+			status.LastSeen = *lastSeen
+		}
+		statuses = append(statuses, &status)
 	}
 	err = rows.Err()
 	return
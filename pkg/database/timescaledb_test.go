@@ -10,6 +10,7 @@ import (
 	"github.com/paulmach/orb"
 	"github.com/paulmach/orb/encoding/wkb"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -408,6 +409,7 @@ func TestTimescaleDB_GetLines(t *testing.T) {
 		Longitude: 1,
 		Latitude:  2,
 		Bearing:   180,
+		Vector:    []float64{0.1, 0.2, 1},
 	}
 
 	type fields struct {
@@ -446,6 +448,8 @@ func TestTimescaleDB_GetLines(t *testing.T) {
 				},
 				LongitudeEnd: 1.0000000000000395,
 				LatitudeEnd:  1.910067839408127,
+				Bearing:      180,
+				Vector:       []float64{0.1, 0.2, 1},
 			}},
 		},
 		{
@@ -471,6 +475,7 @@ func TestTimescaleDB_GetLines(t *testing.T) {
 					},
 					LongitudeEnd: 0.0015695339070129915,
 					LatitudeEnd:  0.08991846347697284,
+					Bearing:      1,
 				},
 				{
 					Position: types.Position{
@@ -479,6 +484,7 @@ func TestTimescaleDB_GetLines(t *testing.T) {
 					},
 					LongitudeEnd: 0.0031385897164049313,
 					LatitudeEnd:  0.08987737630457687,
+					Bearing:      2,
 				},
 			},
 		},
@@ -525,3 +531,126 @@ func TestTimescaleDB_GetLines(t *testing.T) {
 		})
 	}
 }
+
+func TestTimescaleDB_AddPosition(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+	d := &TimescaleDB{
+		Host:         "localhost",
+		Port:         uint16(dockerPort),
+		Username:     "postgres",
+		Password:     "postgres",
+		DatabaseName: "postgres",
+	}
+	if err := d.Connect(); err != nil {
+		t.Fatalf("failed to connect to database: %e", err)
+	}
+
+	if err := d.AddPosition(&types.Position{Timestamp: start}); err == nil {
+		t.Errorf("expected an error for a position without station")
+	}
+
+	for _, position := range []*types.Position{
+		{Timestamp: start, Station: "test_AddPosition", Longitude: 5, Latitude: 52},
+		{Timestamp: start.Add(10 * time.Second), Station: "test_AddPosition", Longitude: 5.1, Latitude: 52.2},
+	} {
+		if err := d.AddPosition(position); err != nil {
+			t.Fatalf("failed to add position: %e", err)
+		}
+	}
+
+	m := &types.Measurement{Timestamp: start.Add(5 * time.Second), Station: "test_AddPosition", Bearing: 90}
+	if err := d.Add(m); err != nil {
+		t.Fatalf("failed to add measurement: %e", err)
+	}
+	if math.Abs(m.Longitude-5.05) > 1e-9 || math.Abs(m.Latitude-52.1) > 1e-9 {
+		t.Errorf("measurement not placed on the track: %v", *m)
+	}
+
+	positions, err := d.GetPositions(time.Minute)
+	if err != nil {
+		t.Fatalf("failed to query for positions: %e", err)
+	}
+	found := 0
+	for _, position := range positions {
+		if position.Station == "test_AddPosition" {
+			found++
+		}
+	}
+	if found != 3 {
+		t.Errorf("expected 3 positions for the station, got %d", found)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	start := time.Now()
+	before := &types.Position{Timestamp: start, Station: "a", Longitude: 1, Latitude: 2}
+	after := &types.Position{Timestamp: start.Add(time.Minute), Station: "a", Longitude: 2, Latitude: 4}
+
+	tests := []struct {
+		name   string
+		before *types.Position
+		after  *types.Position
+		at     time.Time
+		want   *types.Position
+	}{
+		{"between", before, after, start.Add(15 * time.Second), &types.Position{Timestamp: start.Add(15 * time.Second), Station: "a", Longitude: 1.25, Latitude: 2.5}},
+		{"only before", before, nil, start.Add(time.Minute), before},
+		{"only after", nil, after, start, after},
+		{"too old", before, nil, start.Add(maxTrackGap + time.Second), nil},
+		{"nothing", nil, nil, start, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := interpolate(tt.before, tt.after, tt.at); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("interpolate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimescaleDB_AddStatus(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+	d := &TimescaleDB{
+		Host:         "localhost",
+		Port:         uint16(dockerPort),
+		Username:     "postgres",
+		Password:     "postgres",
+		DatabaseName: "postgres",
+	}
+	if err := d.Connect(); err != nil {
+		t.Fatalf("failed to connect to database: %e", err)
+	}
+
+	if err := d.AddStatus(&types.Status{Timestamp: start}); err == nil {
+		t.Errorf("expected an error for a status without station")
+	}
+
+	for _, status := range []*types.Status{
+		{Timestamp: start, Station: "test_AddStatus", Errors: 1, FixAge: -1},
+		{Timestamp: start.Add(time.Second), Station: "test_AddStatus", LastSeen: start, Messages: 3, Bearing: 90, FixAge: 1.5},
+	} {
+		if err := d.AddStatus(status); err != nil {
+			t.Fatalf("failed to add status: %e", err)
+		}
+	}
+
+	statuses, err := d.GetStatus(time.Minute)
+	if err != nil {
+		t.Fatalf("failed to query for status: %e", err)
+	}
+	var found []*types.Status
+	for _, status := range statuses {
+		if status.Station == "test_AddStatus" {
+			found = append(found, status)
+		}
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected 2 reports for the station, got %d", len(found))
+	}
+	if !found[0].LastSeen.IsZero() || found[0].Errors != 1 {
+		t.Errorf("unexpected first report %+v", *found[0])
+	}
+	if !found[1].LastSeen.Equal(start) || found[1].Messages != 3 || found[1].Bearing != 90 || found[1].FixAge != 1.5 {
+		t.Errorf("unexpected second report %+v", *found[1])
+	}
+}
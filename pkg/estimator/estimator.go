@@ -0,0 +1,104 @@
+// Package estimator calculates the most likely transmitter position from a set of bearing lines
+package estimator
+
+import (
+	"errors"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"time"
+)
+
+const earthRadius = 6371000.0 // metres
+
+// Estimate returns the least squares intersection of the bearing lines.
+// The lines are projected onto a flat plane around the centre of the stations, which is accurate enough
+// for the distances involved in a fox hunt.
+func Estimate(lines []*types.Line) (*types.Estimate, error) {
+	if len(lines) < 2 {
+		return nil, errors.New("need at least 2 lines for an estimate")
+	}
+
+	originLongitude, originLatitude := centre(lines)
+
+	// minimise the sum of squared perpendicular distances to all lines: A * p = b
+	var a11, a12, a22, b1, b2 float64
+	var latest time.Time
+	for _, line := range lines {
+		x, y := Project(originLongitude, originLatitude, line.Longitude, line.Latitude)
+		nx, ny := normal(line.Bearing)
+		a11 += nx * nx
+		a12 += nx * ny
+		a22 += ny * ny
+		d := nx*x + ny*y
+		b1 += nx * d
+		b2 += ny * d
+		if line.Timestamp.After(latest) {
+			latest = line.Timestamp
+		}
+	}
+
+	determinant := a11*a22 - a12*a12
+	if determinant < 1e-6 {
+		return nil, errors.New("lines are (nearly) parallel")
+	}
+
+	x := (a22*b1 - a12*b2) / determinant
+	y := (a11*b2 - a12*b1) / determinant
+
+	var residuals float64
+	for _, line := range lines {
+		lx, ly := Project(originLongitude, originLatitude, line.Longitude, line.Latitude)
+		nx, ny := normal(line.Bearing)
+		distance := nx*(x-lx) + ny*(y-ly)
+		residuals += distance * distance
+	}
+
+	longitude, latitude := Unproject(originLongitude, originLatitude, x, y)
+	return &types.Estimate{
+		Timestamp: latest,
+		Longitude: longitude,
+		Latitude:  latitude,
+		Radius:    math.Sqrt(residuals / float64(len(lines))),
+		Lines:     len(lines),
+	}, nil
+}
+
+// Window returns the lines with a timestamp in (end - window, end]
+func Window(lines []*types.Line, end time.Time, window time.Duration) []*types.Line {
+	var result []*types.Line
+	start := end.Add(-window)
+	for _, line := range lines {
+		if line.Timestamp.After(start) && !line.Timestamp.After(end) {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// normal returns the unit vector (east, north) perpendicular to the bearing
+func normal(bearing int) (float64, float64) {
+	radians := float64(bearing) * math.Pi / 180
+	return math.Cos(radians), -math.Sin(radians)
+}
+
+func centre(lines []*types.Line) (longitude, latitude float64) {
+	for _, line := range lines {
+		longitude += line.Longitude
+		latitude += line.Latitude
+	}
+	return longitude / float64(len(lines)), latitude / float64(len(lines))
+}
+
+// Project converts a position to metres east and north of the origin
+func Project(originLongitude, originLatitude, longitude, latitude float64) (float64, float64) {
+	x := (longitude - originLongitude) * math.Pi / 180 * earthRadius * math.Cos(originLatitude*math.Pi/180)
+	y := (latitude - originLatitude) * math.Pi / 180 * earthRadius
+	return x, y
+}
+
+// Unproject converts metres east and north of the origin back to a position
+func Unproject(originLongitude, originLatitude, x, y float64) (float64, float64) {
+	longitude := originLongitude + x/(earthRadius*math.Cos(originLatitude*math.Pi/180))*180/math.Pi
+	latitude := originLatitude + y/earthRadius*180/math.Pi
+	return longitude, latitude
+}
@@ -0,0 +1,83 @@
+package estimator
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/kellydunn/golang-geo"
+	"testing"
+	"time"
+)
+
+func TestEstimate(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tests := []struct {
+		name    string
+		lines   []*types.Line
+		want    *types.Estimate
+		wantErr bool
+	}{
+		{
+			name: "two perpendicular lines",
+			lines: []*types.Line{
+				{Position: types.Position{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99}, Bearing: 0},
+				{Position: types.Position{Timestamp: now.Add(time.Second), Station: "west", Longitude: 4.98, Latitude: 52}, Bearing: 90},
+			},
+			want: &types.Estimate{Timestamp: now.Add(time.Second), Longitude: 5, Latitude: 52, Lines: 2},
+		},
+		{
+			name: "three lines",
+			lines: []*types.Line{
+				{Position: types.Position{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99}, Bearing: 0},
+				{Position: types.Position{Timestamp: now, Station: "west", Longitude: 4.98, Latitude: 52}, Bearing: 90},
+				{Position: types.Position{Timestamp: now, Station: "north", Longitude: 5, Latitude: 52.01}, Bearing: 180},
+			},
+			want: &types.Estimate{Timestamp: now, Longitude: 5, Latitude: 52, Lines: 3},
+		},
+		{
+			name: "single line",
+			lines: []*types.Line{
+				{Position: types.Position{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99}, Bearing: 0},
+			},
+			wantErr: true,
+		},
+		{
+			name: "parallel lines",
+			lines: []*types.Line{
+				{Position: types.Position{Timestamp: now, Station: "a", Longitude: 5, Latitude: 51.99}, Bearing: 0},
+				{Position: types.Position{Timestamp: now, Station: "b", Longitude: 5.01, Latitude: 51.99}, Bearing: 180},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Estimate(tt.lines)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Estimate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			distance := geo.NewPoint(got.Latitude, got.Longitude).GreatCircleDistance(geo.NewPoint(tt.want.Latitude, tt.want.Longitude)) * 1000
+			if distance > 10 {
+				t.Errorf("Estimate() = %v, is %f metres from %v", got, distance, tt.want)
+			}
+			if !got.Timestamp.Equal(tt.want.Timestamp) || got.Lines != tt.want.Lines {
+				t.Errorf("Estimate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	now := time.Now()
+	lines := []*types.Line{
+		{Position: types.Position{Timestamp: now.Add(-2 * time.Minute)}},
+		{Position: types.Position{Timestamp: now.Add(-30 * time.Second)}},
+		{Position: types.Position{Timestamp: now}},
+		{Position: types.Position{Timestamp: now.Add(time.Second)}},
+	}
+	got := Window(lines, now, time.Minute)
+	if len(got) != 2 || got[0] != lines[1] || got[1] != lines[2] {
+		t.Errorf("Window() = %v, want %v", got, lines[1:3])
+	}
+}
@@ -0,0 +1,187 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVFields are the measurement fields that can be mapped to a column
+var CSVFields = []string{"time", "station", "latitude", "longitude", "bearing", "quality", "frequency"}
+
+// CSV reads and writes measurements as CSV with a header row
+type CSV struct {
+	Columns    map[string]string // measurement field to column name
+	TimeFormat string
+	Location   *time.Location // time zone of the times in the file
+}
+
+// NewCSV returns a CSV with the given time zone, that uses the field names as column names and RFC 3339 times
+func NewCSV(location *time.Location) *CSV {
+	columns := map[string]string{}
+	for _, field := range CSVFields {
+		columns[field] = field
+	}
+	return &CSV{
+		Columns:    columns,
+		TimeFormat: time.RFC3339,
+		Location:   location,
+	}
+}
+
+// MapColumns overrides column names with a mapping like "time=Date,bearing=DoA"
+func (c *CSV) MapColumns(mapping string) error {
+	if mapping == "" {
+		return nil
+	}
+	for _, item := range strings.Split(mapping, ",") {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return errors.New("invalid column mapping: " + item)
+		}
+		field := strings.TrimSpace(parts[0])
+		if _, ok := c.Columns[field]; !ok {
+			return errors.New("unknown field in column mapping: " + field)
+		}
+		c.Columns[field] = strings.TrimSpace(parts[1])
+	}
+	return nil
+}
+
+// Write writes the bearing lines as measurements
+func (c *CSV) Write(w io.Writer, lines []*types.Line) error {
+	writer := csv.NewWriter(w)
+	var header []string
+	for _, field := range CSVFields {
+		header = append(header, c.Columns[field])
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		values := map[string]string{
+			"time":      line.Timestamp.In(c.Location).Format(c.TimeFormat),
+			"station":   line.Station,
+			"latitude":  strconv.FormatFloat(line.Latitude, 'f', -1, 64),
+			"longitude": strconv.FormatFloat(line.Longitude, 'f', -1, 64),
+			"bearing":   strconv.Itoa(line.Bearing),
+			"quality":   strconv.FormatFloat(line.Quality, 'f', -1, 64),
+			"frequency": strconv.FormatFloat(line.Frequency, 'f', -1, 64),
+		}
+		var record []string
+		for _, field := range CSVFields {
+			record = append(record, values[field])
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Read returns the measurements in the CSV, and an error for every row that could not be read.
+// The columns for time, station and bearing are required.
+func (c *CSV) Read(r io.Reader) ([]*types.Measurement, []error) {
+	var measurements []*types.Measurement
+	var errs []error
+	err := c.Scan(r, func(row int, m *types.Measurement, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("row %d: %s", row, err))
+			return
+		}
+		measurements = append(measurements, m)
+	})
+	if err != nil {
+		return nil, []error{err}
+	}
+	return measurements, errs
+}
+
+// Scan calls fn for every row of the CSV with its row number, counting the header as row 1,
+// and either the measurement or the reason the row could not be read.
+// It returns an error when the header can not be read or misses a required column.
+func (c *CSV) Scan(r io.Reader, fn func(row int, m *types.Measurement, err error)) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		for field, column := range c.Columns {
+			if strings.TrimSpace(name) == column {
+				index[field] = i
+			}
+		}
+	}
+	for _, field := range []string{"time", "station", "bearing"} {
+		if _, ok := index[field]; !ok {
+			return errors.New("missing column for " + field + ": " + c.Columns[field])
+		}
+	}
+
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			fn(row, nil, err)
+			continue
+		}
+		m, err := c.parse(record, index)
+		fn(row, m, err)
+	}
+}
+
+func (c *CSV) parse(record []string, index map[string]int) (*types.Measurement, error) {
+	value := func(field string) string {
+		i, ok := index[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	number := func(field string) (float64, error) {
+		if value(field) == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(value(field), 64)
+		if err != nil {
+			return 0, errors.New(field + " must be a number")
+		}
+		return f, nil
+	}
+
+	var err error
+	m := types.Measurement{Station: value("station")}
+	if m.Timestamp, err = time.ParseInLocation(c.TimeFormat, value("time"), c.Location); err != nil {
+		return nil, err
+	}
+	bearing, err := number("bearing")
+	if err != nil {
+		return nil, err
+	}
+	m.Bearing = int(math.Round(bearing))
+	for field, target := range map[string]*float64{
+		"latitude":  &m.Latitude,
+		"longitude": &m.Longitude,
+		"quality":   &m.Quality,
+		"frequency": &m.Frequency,
+	} {
+		if *target, err = number(field); err != nil {
+			return nil, err
+		}
+	}
+	return &m, nil
+}
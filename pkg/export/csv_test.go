@@ -0,0 +1,88 @@
+package export
+
+import (
+	"bytes"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSV_Read(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("no time zone database: %s", err)
+	}
+	format := NewCSV(amsterdam)
+	format.TimeFormat = "2006-01-02 15:04:05"
+	if err := format.MapColumns("time=Date, station=Callsign,bearing=DoA,frequency=MHz"); err != nil {
+		t.Fatal(err)
+	}
+
+	input := "Date,Callsign,latitude,longitude,DoA,quality,MHz\n" +
+		"2019-10-01 14:00:00,car1,52.1,5.1,180.4,0.8,144.5\n" +
+		"2019-10-01 14:00:01,car2,,,90,,\n" +
+		"yesterday,car1,52.1,5.1,180,0.8,144.5\n" +
+		"2019-10-01 14:00:02,car1,52.1,5.1,north,0.8,144.5\n"
+	measurements, errs := format.Read(strings.NewReader(input))
+
+	want := []*types.Measurement{
+		{Timestamp: time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC), Station: "car1", Latitude: 52.1, Longitude: 5.1, Bearing: 180, Quality: 0.8, Frequency: 144.5},
+		{Timestamp: time.Date(2019, 10, 1, 12, 0, 1, 0, time.UTC), Station: "car2", Bearing: 90},
+	}
+	if len(measurements) != len(want) {
+		t.Fatalf("Read() returned %v, want %v", measurements, want)
+	}
+	for i := range want {
+		if !measurements[i].Timestamp.Equal(want[i].Timestamp) {
+			t.Errorf("measurement %d has time %s, want %s", i, measurements[i].Timestamp, want[i].Timestamp)
+		}
+		measurements[i].Timestamp = want[i].Timestamp
+		if !reflect.DeepEqual(measurements[i], want[i]) {
+			t.Errorf("measurement %d = %+v, want %+v", i, measurements[i], want[i])
+		}
+	}
+	if len(errs) != 2 || !strings.HasPrefix(errs[0].Error(), "row 4:") || errs[1].Error() != "row 5: bearing must be a number" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestCSV_Read_MissingColumn(t *testing.T) {
+	_, errs := NewCSV(time.UTC).Read(strings.NewReader("time,station\n"))
+	if len(errs) != 1 || errs[0].Error() != "missing column for bearing: bearing" {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestCSV_MapColumns(t *testing.T) {
+	format := NewCSV(time.UTC)
+	if err := format.MapColumns("colour=red"); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+	if err := format.MapColumns("time"); err == nil {
+		t.Errorf("expected an error for a mapping without column")
+	}
+}
+
+func TestCSV_Write(t *testing.T) {
+	format := NewCSV(time.FixedZone("CEST", 2*60*60))
+	format.MapColumns("bearing=DoA")
+	lines := []*types.Line{
+		{Position: types.Position{Timestamp: time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC), Station: "car1", Longitude: 5.1, Latitude: 52.1}, Bearing: 180, Quality: 0.5, Frequency: 144.5},
+	}
+
+	var output bytes.Buffer
+	if err := format.Write(&output, lines); err != nil {
+		t.Fatal(err)
+	}
+	want := "time,station,latitude,longitude,DoA,quality,frequency\n2019-10-01T14:00:00+02:00,car1,52.1,5.1,180,0.5,144.5\n"
+	if output.String() != want {
+		t.Errorf("Write() = %q, want %q", output.String(), want)
+	}
+
+	measurements, errs := format.Read(&output)
+	if len(errs) != 0 || len(measurements) != 1 || measurements[0].Bearing != 180 || !measurements[0].Timestamp.Equal(lines[0].Timestamp) {
+		t.Errorf("could not read back the export: %v, %v", measurements, errs)
+	}
+}
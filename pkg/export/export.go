@@ -0,0 +1,63 @@
+// Package export writes the data of a hunt in formats that other tools can read, and reads it back
+package export
+
+import (
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"hash/fnv"
+	"sort"
+)
+
+// Hunt is the data of a hunt to export
+type Hunt struct {
+	Name      string
+	Positions []*types.Position
+	Lines     []*types.Line
+	Crossings []*types.Crossing
+	Estimates []*types.Estimate
+}
+
+var palette = []string{
+	"#E6194B", "#3CB44B", "#4363D8", "#F58231", "#911EB4",
+	"#42D4F4", "#F032E6", "#BFEF45", "#469990", "#9A6324",
+}
+
+// StationColour returns a fixed colour for the station, as #rrggbb
+func StationColour(station string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(station))
+	return palette[hash.Sum32()%uint32(len(palette))]
+}
+
+// Stations returns the sorted names of all stations in the hunt
+func (h *Hunt) Stations() []string {
+	seen := map[string]bool{}
+	for _, position := range h.Positions {
+		seen[position.Station] = true
+	}
+	for _, line := range h.Lines {
+		seen[line.Station] = true
+	}
+	var stations []string
+	for station := range seen {
+		stations = append(stations, station)
+	}
+	sort.Strings(stations)
+	return stations
+}
+
+// positionsByStation returns the positions of each station, sorted by time
+func (h *Hunt) positionsByStation() map[string][]*types.Position {
+	result := map[string][]*types.Position{}
+	for _, position := range h.Positions {
+		result[position.Station] = append(result[position.Station], position)
+	}
+	for _, positions := range result {
+		sort.SliceStable(positions, func(i, j int) bool { return positions[i].Timestamp.Before(positions[j].Timestamp) })
+	}
+	return result
+}
+
+func coordinate(longitude, latitude float64) string {
+	return fmt.Sprintf("%f,%f", longitude, latitude)
+}
@@ -0,0 +1,169 @@
+package export
+
+import (
+	"encoding/xml"
+	"errors"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"io"
+	"time"
+)
+
+const ardfNamespace = "https://github.com/hsmade/OSM-ARDF/gpx/1"
+
+type gpx struct {
+	XMLName       xml.Name      `xml:"gpx"`
+	Namespace     string        `xml:"xmlns,attr"`
+	ArdfNamespace string        `xml:"xmlns:ardf,attr,omitempty"`
+	Version       string        `xml:"version,attr"`
+	Creator       string        `xml:"creator,attr"`
+	Name          string        `xml:"metadata>name,omitempty"`
+	Waypoints     []gpxWaypoint `xml:"wpt"`
+	Tracks        []gpxTrack    `xml:"trk"`
+}
+
+type gpxWaypoint struct {
+	Latitude   float64        `xml:"lat,attr"`
+	Longitude  float64        `xml:"lon,attr"`
+	Time       string         `xml:"time,omitempty"`
+	Name       string         `xml:"name,omitempty"`
+	Extensions *gpxExtensions `xml:"extensions,omitempty"`
+}
+
+type gpxExtensions struct {
+	Bearing int     `xml:"ardf:bearing"`
+	Suspect bool    `xml:"ardf:suspect,omitempty"`
+	Spread  float64 `xml:"ardf:spread,omitempty"`
+}
+
+type gpxTrack struct {
+	Name     string            `xml:"name"`
+	Segments []gpxTrackSegment `xml:"trkseg"`
+}
+
+type gpxTrackSegment struct {
+	Points []gpxWaypoint `xml:"trkpt"`
+}
+
+// GPX returns the station tracks of the hunt as a GPX 1.1 document, with the bearings as waypoints
+func (h *Hunt) GPX() ([]byte, error) {
+	document := gpx{
+		Namespace:     "http://www.topografix.com/GPX/1/1",
+		ArdfNamespace: ardfNamespace,
+		Version:       "1.1",
+		Creator:       "OSM-ARDF",
+		Name:          h.Name,
+	}
+
+	positionsByStation := h.positionsByStation()
+	for _, station := range h.Stations() {
+		track := gpxTrack{Name: station, Segments: []gpxTrackSegment{{}}}
+		for _, position := range positionsByStation[station] {
+			track.Segments[0].Points = append(track.Segments[0].Points, gpxWaypoint{
+				Latitude:  position.Latitude,
+				Longitude: position.Longitude,
+				Time:      gpxTime(position.Timestamp),
+			})
+		}
+		if len(track.Segments[0].Points) > 0 {
+			document.Tracks = append(document.Tracks, track)
+		}
+	}
+
+	for _, line := range h.Lines {
+		document.Waypoints = append(document.Waypoints, gpxWaypoint{
+			Latitude:   line.Latitude,
+			Longitude:  line.Longitude,
+			Time:       gpxTime(line.Timestamp),
+			Name:       line.Station,
+			Extensions: &gpxExtensions{Bearing: line.Bearing, Suspect: line.Suspect, Spread: line.Spread},
+		})
+	}
+
+	output, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), output...), nil
+}
+
+// ReadGPX returns the track points of all tracks in a GPX document as positions of the station
+func ReadGPX(reader io.Reader, station string) ([]*types.Position, error) {
+	var document struct {
+		Tracks []struct {
+			Segments []struct {
+				Points []struct {
+					Latitude  float64 `xml:"lat,attr"`
+					Longitude float64 `xml:"lon,attr"`
+					Time      string  `xml:"time"`
+				} `xml:"trkpt"`
+			} `xml:"trkseg"`
+		} `xml:"trk"`
+	}
+	if err := xml.NewDecoder(reader).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	var positions []*types.Position
+	for _, track := range document.Tracks {
+		for _, segment := range track.Segments {
+			for _, point := range segment.Points {
+				timestamp, err := time.Parse(time.RFC3339, point.Time)
+				if err != nil {
+					return nil, errors.New("track point without a valid time: " + point.Time)
+				}
+				positions = append(positions, &types.Position{
+					Timestamp: timestamp,
+					Station:   station,
+					Longitude: point.Longitude,
+					Latitude:  point.Latitude,
+				})
+			}
+		}
+	}
+	return positions, nil
+}
+
+// ReadGPXMeasurements returns the bearings that GPX exported as waypoints. Waypoints without a bearing are skipped.
+func ReadGPXMeasurements(reader io.Reader) ([]*types.Measurement, error) {
+	var document struct {
+		Waypoints []struct {
+			Latitude   float64 `xml:"lat,attr"`
+			Longitude  float64 `xml:"lon,attr"`
+			Time       string  `xml:"time"`
+			Name       string  `xml:"name"`
+			Extensions struct {
+				Bearing *int    `xml:"bearing"`
+				Suspect bool    `xml:"suspect"`
+				Spread  float64 `xml:"spread"`
+			} `xml:"extensions"`
+		} `xml:"wpt"`
+	}
+	if err := xml.NewDecoder(reader).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	var measurements []*types.Measurement
+	for _, waypoint := range document.Waypoints {
+		if waypoint.Extensions.Bearing == nil {
+			continue
+		}
+		timestamp, err := time.Parse(time.RFC3339, waypoint.Time)
+		if err != nil {
+			return nil, errors.New("waypoint without a valid time: " + waypoint.Time)
+		}
+		measurements = append(measurements, &types.Measurement{
+			Timestamp: timestamp,
+			Station:   waypoint.Name,
+			Longitude: waypoint.Longitude,
+			Latitude:  waypoint.Latitude,
+			Bearing:   *waypoint.Extensions.Bearing,
+			Suspect:   waypoint.Extensions.Suspect,
+			Spread:    waypoint.Extensions.Spread,
+		})
+	}
+	return measurements, nil
+}
+
+func gpxTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHunt_GPX(t *testing.T) {
+	data, err := testHunt().GPX()
+	if err != nil {
+		t.Fatalf("GPX() returned error: %e", err)
+	}
+	got := string(data)
+
+	for _, want := range []string{
+		`<gpx xmlns="http://www.topografix.com/GPX/1/1" xmlns:ardf="https://github.com/hsmade/OSM-ARDF/gpx/1" version="1.1" creator="OSM-ARDF">`,
+		`<wpt lat="52" lon="5.2">`,
+		`<ardf:bearing>0</ardf:bearing>`,
+		`<ardf:suspect>true</ardf:suspect>`,
+		`<trkpt lat="52" lon="5">`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in gpx:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "<trk>") != 2 {
+		t.Errorf("expected a track per station:\n%s", got)
+	}
+
+	// the export can be imported again
+	positions, err := ReadGPX(bytes.NewReader(data), "car 1")
+	if err != nil {
+		t.Fatalf("ReadGPX() returned error: %e", err)
+	}
+	if len(positions) != 3 {
+		t.Errorf("expected 3 positions, got %v", positions)
+	}
+}
+
+func TestReadGPX(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="phone" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk><name>drive</name>
+    <trkseg>
+      <trkpt lat="52.1" lon="5.1"><ele>3</ele><time>2019-10-01T12:00:00Z</time></trkpt>
+      <trkpt lat="52.2" lon="5.2"><time>2019-10-01T12:00:05.5Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+	positions, err := ReadGPX(strings.NewReader(input), "car1")
+	if err != nil {
+		t.Fatalf("ReadGPX() returned error: %e", err)
+	}
+	start := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	if len(positions) != 2 {
+		t.Fatalf("expected 2 positions, got %v", positions)
+	}
+	if !positions[0].Timestamp.Equal(start) || positions[0].Station != "car1" || positions[0].Longitude != 5.1 || positions[0].Latitude != 52.1 {
+		t.Errorf("unexpected first position: %+v", positions[0])
+	}
+	if !reflect.DeepEqual(positions[1].Timestamp.UTC(), start.Add(5500*time.Millisecond)) {
+		t.Errorf("unexpected time of second position: %s", positions[1].Timestamp)
+	}
+
+	if _, err := ReadGPX(strings.NewReader(`<gpx><trk><trkseg><trkpt lat="1" lon="2"/></trkseg></trk></gpx>`), "car1"); err == nil {
+		t.Errorf("expected an error for a track point without time")
+	}
+}
+
+func TestReadGPXMeasurements(t *testing.T) {
+	data, err := testHunt().GPX()
+	if err != nil {
+		t.Fatal(err)
+	}
+	measurements, err := ReadGPXMeasurements(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadGPXMeasurements() returned error: %e", err)
+	}
+	line := testHunt().Lines[0]
+	if len(measurements) != 1 || measurements[0].Station != line.Station || measurements[0].Bearing != line.Bearing ||
+		!measurements[0].Suspect || !measurements[0].Timestamp.Equal(line.Timestamp) || measurements[0].Latitude != line.Latitude {
+		t.Errorf("ReadGPXMeasurements() = %v, want the bearing line %v", measurements, line)
+	}
+}
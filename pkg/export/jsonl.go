@@ -0,0 +1,42 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"io"
+)
+
+// ReadJSONL returns the measurements in the JSON lines format the stdin receiver uses,
+// and an error for every line that could not be read
+func ReadJSONL(r io.Reader) ([]*types.Measurement, []error) {
+	var measurements []*types.Measurement
+	var errs []error
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		m := types.Measurement{}
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %s", line, err))
+			continue
+		}
+		measurements = append(measurements, &m)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return measurements, errs
+}
+
+// WriteJSONL writes the measurement as a single line that the stdin receiver can read
+func WriteJSONL(w io.Writer, m *types.Measurement) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
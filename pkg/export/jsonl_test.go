@@ -0,0 +1,39 @@
+package export
+
+import (
+	"bytes"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadJSONL(t *testing.T) {
+	input := "{\"timestamp\":\"2018-09-22T12:42:31Z\", \"station\":\"abc\", \"longitude\": 5.0, \"latitude\": 52.5, \"bearing\": 180}\n" +
+		"\n" +
+		"garbage\n"
+	measurements, errs := ReadJSONL(strings.NewReader(input))
+	want := []*types.Measurement{{Timestamp: time.Date(2018, 9, 22, 12, 42, 31, 0, time.UTC), Station: "abc", Longitude: 5, Latitude: 52.5, Bearing: 180}}
+	if !reflect.DeepEqual(measurements, want) {
+		t.Errorf("ReadJSONL() = %v, want %v", measurements, want)
+	}
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "line 3:") {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	m := &types.Measurement{Timestamp: time.Date(2018, 9, 22, 12, 42, 31, 0, time.UTC), Station: "abc", Bearing: 180, Quality: 1}
+	var output bytes.Buffer
+	if err := WriteJSONL(&output, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteJSONL(&output, m); err != nil {
+		t.Fatal(err)
+	}
+	measurements, errs := ReadJSONL(&output)
+	if len(errs) != 0 || len(measurements) != 2 || !reflect.DeepEqual(measurements[1], m) {
+		t.Errorf("could not read back %s: %v", output.String(), errs)
+	}
+}
@@ -0,0 +1,196 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type kml struct {
+	XMLName   xml.Name    `xml:"kml"`
+	Namespace string      `xml:"xmlns,attr"`
+	Document  kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name    string      `xml:"name"`
+	Styles  []kmlStyle  `xml:"Style"`
+	Folders []kmlFolder `xml:"Folder"`
+}
+
+type kmlStyle struct {
+	ID        string `xml:"id,attr"`
+	IconColor string `xml:"IconStyle>color"`
+	LineColor string `xml:"LineStyle>color"`
+	LineWidth int    `xml:"LineStyle>width"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string         `xml:"name"`
+	Description string         `xml:"description,omitempty"`
+	TimeStamp   *kmlTimeStamp  `xml:"TimeStamp,omitempty"`
+	TimeSpan    *kmlTimeSpan   `xml:"TimeSpan,omitempty"`
+	StyleURL    string         `xml:"styleUrl,omitempty"`
+	Point       *kmlCoordinate `xml:"Point,omitempty"`
+	LineString  *kmlCoordinate `xml:"LineString,omitempty"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlTimeSpan struct {
+	Begin string `xml:"begin"`
+	End   string `xml:"end"`
+}
+
+type kmlCoordinate struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// KML returns the hunt as a KML document, with time stamps for the time slider in Google Earth
+func (h *Hunt) KML() ([]byte, error) {
+	document := kml{
+		Namespace: "http://www.opengis.net/kml/2.2",
+		Document:  kmlDocument{Name: h.Name},
+	}
+
+	for _, station := range h.Stations() {
+		document.Document.Styles = append(document.Document.Styles, kmlStyle{
+			ID:        styleID(station),
+			IconColor: kmlColour(StationColour(station)),
+			LineColor: kmlColour(StationColour(station)),
+			LineWidth: 2,
+		})
+	}
+	document.Document.Styles = append(document.Document.Styles,
+		kmlStyle{ID: "crossing", IconColor: kmlColour("#FF0000"), LineColor: kmlColour("#FF0000"), LineWidth: 1},
+		kmlStyle{ID: "estimate", IconColor: kmlColour("#FFFF00"), LineColor: kmlColour("#FFFF00"), LineWidth: 1},
+	)
+
+	tracks := kmlFolder{Name: "Tracks"}
+	positionsByStation := h.positionsByStation()
+	for _, station := range h.Stations() {
+		positions := positionsByStation[station]
+		if len(positions) < 2 {
+			continue
+		}
+		var coordinates []string
+		for _, position := range positions {
+			coordinates = append(coordinates, coordinate(position.Longitude, position.Latitude))
+		}
+		tracks.Placemarks = append(tracks.Placemarks, kmlPlacemark{
+			Name:       station,
+			TimeSpan:   &kmlTimeSpan{Begin: kmlTime(positions[0].Timestamp), End: kmlTime(positions[len(positions)-1].Timestamp)},
+			StyleURL:   "#" + styleID(station),
+			LineString: &kmlCoordinate{Coordinates: strings.Join(coordinates, " ")},
+		})
+	}
+
+	positions := kmlFolder{Name: "Positions"}
+	for _, position := range h.Positions {
+		positions.Placemarks = append(positions.Placemarks, kmlPlacemark{
+			Name:      position.Station,
+			TimeStamp: &kmlTimeStamp{When: kmlTime(position.Timestamp)},
+			StyleURL:  "#" + styleID(position.Station),
+			Point:     &kmlCoordinate{Coordinates: coordinate(position.Longitude, position.Latitude)},
+		})
+	}
+
+	bearings := kmlFolder{Name: "Bearings"}
+	for _, line := range h.Lines {
+		description := fmt.Sprintf("bearing %d°", line.Bearing)
+		if line.Suspect {
+			description += ", suspect"
+		}
+		bearings.Placemarks = append(bearings.Placemarks, kmlPlacemark{
+			Name:        line.Station,
+			Description: description,
+			TimeStamp:   &kmlTimeStamp{When: kmlTime(line.Timestamp)},
+			StyleURL:    "#" + styleID(line.Station),
+			LineString: &kmlCoordinate{Coordinates: coordinate(line.Longitude, line.Latitude) + " " +
+				coordinate(line.LongitudeEnd, line.LatitudeEnd)},
+		})
+	}
+
+	crossings := kmlFolder{Name: "Crossings"}
+	for _, crossing := range h.Crossings {
+		placemark := kmlPlacemark{
+			Name:        fmt.Sprintf("%d crossings", crossing.Weight),
+			Description: fmt.Sprintf("stations %s, spread %.0f m", strings.Join(crossing.Stations, ", "), crossing.Spread),
+			StyleURL:    "#crossing",
+			Point:       &kmlCoordinate{Coordinates: coordinate(crossing.Longitude, crossing.Latitude)},
+		}
+		if !crossing.Timestamp.IsZero() {
+			placemark.TimeStamp = &kmlTimeStamp{When: kmlTime(crossing.Timestamp)}
+		}
+		crossings.Placemarks = append(crossings.Placemarks, placemark)
+	}
+
+	estimates := kmlFolder{Name: "Estimates"}
+	for _, estimate := range h.Estimates {
+		estimates.Placemarks = append(estimates.Placemarks, kmlPlacemark{
+			Name:        "estimate",
+			Description: fmt.Sprintf("uncertainty %.0f m from %d bearings", estimate.Radius, estimate.Lines),
+			TimeStamp:   &kmlTimeStamp{When: kmlTime(estimate.Timestamp)},
+			StyleURL:    "#estimate",
+			Point:       &kmlCoordinate{Coordinates: coordinate(estimate.Longitude, estimate.Latitude)},
+		})
+	}
+
+	document.Document.Folders = []kmlFolder{tracks, positions, bearings, crossings, estimates}
+	output, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), output...), nil
+}
+
+// KMZ returns the hunt as a zipped KML document
+func (h *Hunt) KMZ() ([]byte, error) {
+	document, err := h.KML()
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	writer, err := archive.Create("doc.kml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(document); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// styleID returns the station name as a valid XML id
+func styleID(station string) string {
+	return "station-" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, station)
+}
+
+// kmlColour converts #rrggbb into the aabbggrr that KML uses
+func kmlColour(colour string) string {
+	return "ff" + colour[5:7] + colour[3:5] + colour[1:3]
+}
+
+func kmlTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
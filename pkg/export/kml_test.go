@@ -0,0 +1,95 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func testHunt() *Hunt {
+	start := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	return &Hunt{
+		Name: "test",
+		Positions: []*types.Position{
+			{Timestamp: start.Add(time.Minute), Station: "car 1", Longitude: 5.1, Latitude: 52.1},
+			{Timestamp: start, Station: "car 1", Longitude: 5, Latitude: 52},
+			{Timestamp: start, Station: "car2", Longitude: 5.2, Latitude: 52},
+		},
+		Lines: []*types.Line{
+			{Position: types.Position{Timestamp: start, Station: "car2", Longitude: 5.2, Latitude: 52}, LongitudeEnd: 5.2, LatitudeEnd: 52.2, Bearing: 0, Suspect: true},
+		},
+		Crossings: []*types.Crossing{{Timestamp: start, Longitude: 5.2, Latitude: 52.1, Weight: 2, Stations: []string{"car 1", "car2"}}},
+		Estimates: []*types.Estimate{{Timestamp: start, Longitude: 5.2, Latitude: 52.1, Radius: 10, Lines: 2}},
+	}
+}
+
+func TestHunt_KML(t *testing.T) {
+	data, err := testHunt().KML()
+	if err != nil {
+		t.Fatalf("KML() returned error: %e", err)
+	}
+
+	var got kml
+	if err := xml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("failed to parse kml: %e\n%s", err, data)
+	}
+	if len(got.Document.Styles) != 4 || got.Document.Styles[0].ID != "station-car_1" {
+		t.Errorf("unexpected styles: %+v", got.Document.Styles)
+	}
+
+	folders := map[string]kmlFolder{}
+	for _, folder := range got.Document.Folders {
+		folders[folder.Name] = folder
+	}
+	track := folders["Tracks"].Placemarks
+	if len(track) != 1 || track[0].TimeSpan.Begin != "2019-10-01T12:00:00Z" || track[0].LineString.Coordinates != "5.000000,52.000000 5.100000,52.100000" {
+		t.Errorf("unexpected tracks: %+v", track)
+	}
+	if len(folders["Positions"].Placemarks) != 3 {
+		t.Errorf("expected 3 positions, got %+v", folders["Positions"].Placemarks)
+	}
+	bearings := folders["Bearings"].Placemarks
+	if len(bearings) != 1 || bearings[0].TimeStamp.When != "2019-10-01T12:00:00Z" || bearings[0].Description != "bearing 0°, suspect" {
+		t.Errorf("unexpected bearings: %+v", bearings)
+	}
+	crossings, estimates := folders["Crossings"].Placemarks, folders["Estimates"].Placemarks
+	if len(crossings) != 1 || len(estimates) != 1 {
+		t.Fatalf("expected a crossing and an estimate, got %+v", got.Document.Folders)
+	}
+	if crossings[0].TimeStamp == nil || crossings[0].TimeStamp.When != "2019-10-01T12:00:00Z" || estimates[0].TimeStamp == nil {
+		t.Errorf("expected time stamps on the crossing and the estimate, got %+v, %+v", crossings[0], estimates[0])
+	}
+}
+
+func TestHunt_KMZ(t *testing.T) {
+	data, err := testHunt().KMZ()
+	if err != nil {
+		t.Fatalf("KMZ() returned error: %e", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("failed to open kmz: %e", err)
+	}
+	if len(archive.File) != 1 || archive.File[0].Name != "doc.kml" {
+		t.Fatalf("unexpected files in kmz: %v", archive.File)
+	}
+	file, err := archive.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadAll(file)
+	want, _ := testHunt().KML()
+	if !bytes.Equal(content, want) {
+		t.Errorf("kmz content differs from kml")
+	}
+}
+
+func TestKmlColour(t *testing.T) {
+	if got := kmlColour("#112233"); got != "ff332211" {
+		t.Errorf("kmlColour() = %s, want ff332211", got)
+	}
+}
@@ -0,0 +1,105 @@
+package export
+
+import (
+	"fmt"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+	"strings"
+	"time"
+)
+
+// TileLayers are the layers that MVT can render
+var TileLayers = []string{"positions", "lines", "crossings", "estimates"}
+
+// tileBuffer is the part of the neighbouring tiles that is included, so symbols on the edge aren't cut off
+const tileBuffer = 0.1
+
+// MVT renders a layer of the hunt as a Mapbox Vector Tile, or all layers when layer is empty.
+// The properties match those of the GeoJSON API.
+func (h *Hunt) MVT(layer string, tile maptile.Tile) ([]byte, error) {
+	if !tile.Valid() {
+		return nil, fmt.Errorf("invalid tile %d/%d/%d", tile.Z, tile.X, tile.Y)
+	}
+	names := TileLayers
+	if layer != "" {
+		names = []string{layer}
+	}
+
+	bound := tile.Bound(tileBuffer)
+	var layers mvt.Layers
+	for _, name := range names {
+		collection, err := h.featureCollection(name)
+		if err != nil {
+			return nil, err
+		}
+		visible := geojson.NewFeatureCollection()
+		for _, feature := range collection.Features {
+			if feature.Geometry.Bound().Intersects(bound) {
+				visible.Append(feature)
+			}
+		}
+		layers = append(layers, mvt.NewLayer(name, visible))
+	}
+
+	layers.ProjectToTile(tile)
+	layers.Clip(mvt.MapboxGLDefaultExtentBound)
+	for _, l := range layers {
+		var kept []*geojson.Feature
+		for _, feature := range l.Features {
+			if feature.Geometry != nil {
+				kept = append(kept, feature)
+			}
+		}
+		l.Features = kept
+	}
+	return mvt.Marshal(layers)
+}
+
+// featureCollection returns the features of a layer
+func (h *Hunt) featureCollection(layer string) (*geojson.FeatureCollection, error) {
+	fc := geojson.NewFeatureCollection()
+	switch layer {
+	case "positions":
+		for _, position := range h.Positions {
+			feature := geojson.NewFeature(orb.Point{position.Longitude, position.Latitude})
+			feature.Properties["station"] = position.Station
+			feature.Properties["time"] = position.Timestamp.Format(time.RFC3339)
+			feature.Properties["colour"] = StationColour(position.Station)
+			fc.Append(feature)
+		}
+	case "lines":
+		for _, line := range h.Lines {
+			feature := geojson.NewFeature(orb.LineString{{line.Longitude, line.Latitude}, {line.LongitudeEnd, line.LatitudeEnd}})
+			feature.Properties["station"] = line.Station
+			feature.Properties["time"] = line.Timestamp.Format(time.RFC3339)
+			feature.Properties["bearing"] = line.Bearing
+			feature.Properties["suspect"] = line.Suspect
+			feature.Properties["spread"] = line.Spread
+			feature.Properties["colour"] = StationColour(line.Station)
+			fc.Append(feature)
+		}
+	case "crossings":
+		for _, crossing := range h.Crossings {
+			feature := geojson.NewFeature(orb.Point{crossing.Longitude, crossing.Latitude})
+			feature.Properties["weight"] = crossing.Weight
+			feature.Properties["stations"] = strings.Join(crossing.Stations, ",")
+			feature.Properties["spread"] = crossing.Spread
+			feature.Properties["angle"] = crossing.Angle
+			feature.Properties["dilution"] = crossing.Dilution
+			fc.Append(feature)
+		}
+	case "estimates":
+		for _, estimate := range h.Estimates {
+			feature := geojson.NewFeature(orb.Point{estimate.Longitude, estimate.Latitude})
+			feature.Properties["time"] = estimate.Timestamp.Format(time.RFC3339)
+			feature.Properties["radius"] = estimate.Radius
+			feature.Properties["lines"] = estimate.Lines
+			fc.Append(feature)
+		}
+	default:
+		return nil, fmt.Errorf("unknown layer %s, use one of %s", layer, strings.Join(TileLayers, ", "))
+	}
+	return fc, nil
+}
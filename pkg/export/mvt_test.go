@@ -0,0 +1,69 @@
+package export
+
+import (
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/maptile"
+	"math"
+	"testing"
+)
+
+func TestHunt_MVT(t *testing.T) {
+	tile := maptile.At(orb.Point{5.2, 52.1}, 12)
+	data, err := testHunt().MVT("", tile)
+	if err != nil {
+		t.Fatalf("MVT() returned error: %e", err)
+	}
+	layers, err := mvt.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("failed to parse tile: %e", err)
+	}
+	layers.ProjectToWGS84(tile)
+
+	counts := map[string]int{}
+	for _, layer := range layers {
+		counts[layer.Name] = len(layer.Features)
+	}
+	// only car2 is on this tile, the line crosses it
+	want := map[string]int{"positions": 0, "lines": 1, "crossings": 1, "estimates": 1}
+	for name, count := range want {
+		if counts[name] != count {
+			t.Errorf("layer %s has %d features, want %d", name, counts[name], count)
+		}
+	}
+
+	estimate := layers.ToFeatureCollections()["estimates"].Features[0]
+	point := estimate.Geometry.(orb.Point)
+	if math.Abs(point.Lon()-5.2) > 0.001 || math.Abs(point.Lat()-52.1) > 0.001 {
+		t.Errorf("unexpected estimate position %v", point)
+	}
+	if estimate.Properties["lines"] != 2.0 || estimate.Properties["radius"] != 10.0 {
+		t.Errorf("unexpected estimate properties %v", estimate.Properties)
+	}
+	line := layers.ToFeatureCollections()["lines"].Features[0]
+	if line.Properties["station"] != "car2" || line.Properties["suspect"] != true {
+		t.Errorf("unexpected line properties %v", line.Properties)
+	}
+}
+
+func TestHunt_MVT_Layer(t *testing.T) {
+	tile := maptile.At(orb.Point{5, 52}, 10)
+	data, err := testHunt().MVT("positions", tile)
+	if err != nil {
+		t.Fatalf("MVT() returned error: %e", err)
+	}
+	layers, err := mvt.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("failed to parse tile: %e", err)
+	}
+	if len(layers) != 1 || layers[0].Name != "positions" || len(layers[0].Features) != 2 {
+		t.Errorf("unexpected layers %v", layers)
+	}
+
+	if _, err := testHunt().MVT("heatmap", tile); err == nil {
+		t.Errorf("expected an error for an unknown layer")
+	}
+	if _, err := testHunt().MVT("", maptile.New(4, 0, 1)); err == nil {
+		t.Errorf("expected an error for an invalid tile")
+	}
+}
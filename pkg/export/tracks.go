@@ -0,0 +1,96 @@
+package export
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/kellydunn/golang-geo"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/project"
+	"github.com/paulmach/orb/simplify"
+	"math"
+	"time"
+)
+
+const (
+	// movementWindow is how far back the speed and heading of a station are taken from
+	movementWindow = 30 * time.Second
+	// minimumMovement is the distance in metres a station must move to have a heading, as GPS positions jitter
+	minimumMovement = 10.0
+)
+
+// Track is the path a station drove
+type Track struct {
+	Station   string
+	Start     time.Time
+	End       time.Time
+	Positions int // before simplification
+	Path      orb.LineString
+}
+
+// Movement is the latest position of a station, with the speed and heading over the last 30 seconds
+type Movement struct {
+	types.Position
+	Speed      float64 // metres per second
+	Heading    float64 // degrees
+	HasHeading bool    // false when the station didn't move enough
+}
+
+// Tracks returns the path of each station, sorted by station. The paths are simplified with Douglas-Peucker,
+// so that no position is left out that is further than tolerance metres from the path.
+func (h *Hunt) Tracks(tolerance float64) []*Track {
+	byStation := h.positionsByStation()
+	var tracks []*Track
+	for _, station := range h.Stations() {
+		positions := byStation[station]
+		if len(positions) == 0 {
+			continue
+		}
+		path := make(orb.LineString, 0, len(positions))
+		for _, position := range positions {
+			path = append(path, project.WGS84.ToMercator(orb.Point{position.Longitude, position.Latitude}))
+		}
+		if tolerance > 0 {
+			// Mercator metres are stretched away from the equator
+			scale := project.MercatorScaleFactor(orb.Point{positions[0].Longitude, positions[0].Latitude})
+			path = simplify.DouglasPeucker(tolerance * scale).LineString(path)
+		}
+		tracks = append(tracks, &Track{
+			Station:   station,
+			Start:     positions[0].Timestamp,
+			End:       positions[len(positions)-1].Timestamp,
+			Positions: len(positions),
+			Path:      project.LineString(path, project.Mercator.ToWGS84),
+		})
+	}
+	return tracks
+}
+
+// Latest returns the latest position of each station, sorted by station
+func (h *Hunt) Latest() []*Movement {
+	byStation := h.positionsByStation()
+	var movements []*Movement
+	for _, station := range h.Stations() {
+		positions := byStation[station]
+		if len(positions) == 0 {
+			continue
+		}
+		latest := positions[len(positions)-1]
+		movement := &Movement{Position: *latest}
+
+		earliest := latest
+		for i := len(positions) - 2; i >= 0 && latest.Timestamp.Sub(positions[i].Timestamp) <= movementWindow; i-- {
+			earliest = positions[i]
+		}
+		if duration := latest.Timestamp.Sub(earliest.Timestamp).Seconds(); duration > 0 {
+			from := geo.NewPoint(earliest.Latitude, earliest.Longitude)
+			to := geo.NewPoint(latest.Latitude, latest.Longitude)
+			distance := from.GreatCircleDistance(to) * 1000
+			movement.Speed = distance / duration
+			if distance >= minimumMovement {
+				movement.Heading = math.Mod(from.BearingTo(to)+360, 360)
+				movement.HasHeading = true
+			}
+		}
+		movements = append(movements, movement)
+	}
+	return movements
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"testing"
+	"time"
+)
+
+// drive returns positions of a station driving 10 m/s north for 100 seconds, then east for 100 seconds
+func drive(station string, start time.Time) []*types.Position {
+	var positions []*types.Position
+	longitude, latitude := 5.0, 52.0
+	for i := 0; i < 200; i++ {
+		if i < 100 {
+			latitude += 10 / 111195.0
+		} else {
+			longitude += 10 / (111195.0 * math.Cos(52*math.Pi/180))
+		}
+		// a little GPS jitter
+		jitter := float64(i%3-1) * 0.5 / 111195.0
+		positions = append(positions, &types.Position{
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Station:   station,
+			Longitude: longitude + jitter,
+			Latitude:  latitude + jitter,
+		})
+	}
+	return positions
+}
+
+func TestHunt_Tracks(t *testing.T) {
+	start := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	positions := drive("car1", start)
+	// out of order, as the database returns them
+	positions[10], positions[150] = positions[150], positions[10]
+	hunt := &Hunt{Positions: append(positions, &types.Position{Timestamp: start, Station: "car2", Longitude: 5.1, Latitude: 52.1})}
+
+	tracks := hunt.Tracks(5)
+	if len(tracks) != 2 || tracks[0].Station != "car1" || tracks[1].Station != "car2" {
+		t.Fatalf("unexpected tracks %v", tracks)
+	}
+	car1 := tracks[0]
+	if car1.Positions != 200 || !car1.Start.Equal(start) || !car1.End.Equal(start.Add(199*time.Second)) {
+		t.Errorf("unexpected track %+v", *car1)
+	}
+	if len(car1.Path) != 3 {
+		t.Errorf("expected the corner to remain, got %d points: %v", len(car1.Path), car1.Path)
+	}
+	corner := car1.Path[1]
+	if math.Abs(corner.Lat()-(52+1000/111195.0)) > 0.0001 || math.Abs(corner.Lon()-5) > 0.0001 {
+		t.Errorf("unexpected corner %v", corner)
+	}
+	if len(tracks[1].Path) != 1 {
+		t.Errorf("expected a single point for car2, got %v", tracks[1].Path)
+	}
+
+	if tracks := hunt.Tracks(0); len(tracks[0].Path) != 200 {
+		t.Errorf("expected all positions without a tolerance, got %d", len(tracks[0].Path))
+	}
+}
+
+func TestHunt_Latest(t *testing.T) {
+	start := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	hunt := &Hunt{Positions: append(drive("car1", start),
+		&types.Position{Timestamp: start, Station: "car2", Longitude: 5.1, Latitude: 52.1},
+		&types.Position{Timestamp: start.Add(10 * time.Second), Station: "car2", Longitude: 5.1, Latitude: 52.10001},
+	)}
+
+	latest := hunt.Latest()
+	if len(latest) != 2 {
+		t.Fatalf("expected 2 stations, got %d", len(latest))
+	}
+	car1 := latest[0]
+	if car1.Station != "car1" || !car1.Timestamp.Equal(start.Add(199*time.Second)) {
+		t.Errorf("unexpected latest position %+v", car1.Position)
+	}
+	if math.Abs(car1.Speed-10) > 0.5 || !car1.HasHeading || math.Abs(car1.Heading-90) > 1 {
+		t.Errorf("expected 10 m/s to the east, got %f m/s, %f", car1.Speed, car1.Heading)
+	}
+	// car2 barely moves
+	if latest[1].HasHeading || latest[1].Speed > 0.2 {
+		t.Errorf("unexpected movement %+v", *latest[1])
+	}
+}
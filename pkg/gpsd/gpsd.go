@@ -0,0 +1,92 @@
+// Package gpsd provides the position of a station from a gpsd, over its JSON protocol
+package gpsd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"github.com/apex/log"
+	"github.com/hsmade/OSM-ARDF/pkg/position"
+	"net"
+	"time"
+)
+
+// watch enables the JSON reports
+const watch = "?WATCH={\"enable\":true,\"json\":true};\n"
+
+// report is a gpsd report, only the fields of a TPV (time-position-velocity) report are read
+type report struct {
+	Class string    `json:"class"`
+	Mode  int       `json:"mode"` // 0 and 1 mean no fix, 2 is a 2D fix and 3 a 3D fix
+	Time  time.Time `json:"time"`
+	Lat   *float64  `json:"lat"`
+	Lon   *float64  `json:"lon"`
+	Track *float64  `json:"track"` // course over ground, only when moving
+}
+
+// Client keeps the latest fix of a gpsd
+type Client struct {
+	*position.Latest
+	Address string        // like localhost:2947
+	Retry   time.Duration // time between connection attempts
+}
+
+// New returns a client that provides fixes up to maxAge old. Start it with Start or Watch.
+func New(address string, maxAge time.Duration) *Client {
+	return &Client{
+		Latest:  position.NewLatest(maxAge),
+		Address: address,
+		Retry:   5 * time.Second,
+	}
+}
+
+// Start watches gpsd, and reconnects when the connection fails
+func (c *Client) Start() {
+	for {
+		err := c.Watch()
+		log.WithError(err).Warnf("Lost connection to gpsd at %s", c.Address)
+		c.Lost()
+		time.Sleep(c.Retry)
+	}
+}
+
+// Watch connects to gpsd, and keeps the latest fix until the connection fails
+func (c *Client) Watch() error {
+	conn, err := net.Dial("tcp", c.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(watch)); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var r report
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			log.WithError(err).Warn("Failed to parse gpsd report")
+			continue
+		}
+		if r.Class != "TPV" {
+			continue
+		}
+		if r.Mode < 2 || r.Lat == nil || r.Lon == nil {
+			c.Lost()
+			continue
+		}
+		fix := &position.Fix{
+			Timestamp: r.Time,
+			Longitude: *r.Lon,
+			Latitude:  *r.Lat,
+		}
+		if r.Track != nil {
+			fix.Course, fix.HasCourse = *r.Track, true
+		}
+		c.Update(fix)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.New("connection closed")
+}
@@ -0,0 +1,115 @@
+package gpsd
+
+import (
+	"bufio"
+	"github.com/hsmade/OSM-ARDF/pkg/position"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeGpsd serves the reports after receiving the watch command, and waits for the next report until next is called
+func fakeGpsd(t *testing.T, reports ...string) (address string, next func(), received <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := make(chan struct{})
+	commands := make(chan string, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte(`{"class":"VERSION","release":"3.17","rev":"3.17","proto_major":3,"proto_minor":12}` + "\n"))
+		command, _ := bufio.NewReader(conn).ReadString('\n')
+		commands <- command
+		for _, report := range reports {
+			<-steps
+			_, _ = conn.Write([]byte(report + "\n"))
+		}
+		<-steps
+	}()
+	return listener.Addr().String(), func() { steps <- struct{}{} }, commands
+}
+
+func TestClient_Watch(t *testing.T) {
+	address, next, commands := fakeGpsd(t,
+		`{"class":"DEVICES","devices":[{"class":"DEVICE","path":"/dev/ttyUSB0","activated":"2019-10-02T12:00:00.000Z"}]}`+"\n"+
+			`{"class":"TPV","device":"/dev/ttyUSB0","mode":3,"time":"2019-10-02T12:00:00.000Z","lat":52.0901,"lon":5.1214,"alt":10.0,"track":270.5,"speed":6.4}`,
+		`{"class":"SKY","device":"/dev/ttyUSB0","satellites":[]}`+"\n"+
+			`{"class":"TPV","device":"/dev/ttyUSB0","mode":2,"time":"2019-10-02T12:00:01.000Z","lat":52.0902,"lon":5.1215}`,
+		`{"class":"TPV","device":"/dev/ttyUSB0","mode":1,"time":"2019-10-02T12:00:02.000Z"}`,
+	)
+	client := New(address, time.Minute)
+	done := make(chan error)
+	go func() {
+		done <- client.Watch()
+	}()
+
+	if command := <-commands; command != watch {
+		t.Errorf("unexpected command %q", command)
+	}
+
+	next()
+	fix := waitForFix(t, client, 5.1214)
+	if fix.Latitude != 52.0901 || !fix.HasCourse || fix.Course != 270.5 || !fix.Timestamp.Equal(time.Date(2019, 10, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected fix: %v", fix)
+	}
+
+	// a 2D fix without a track keeps the course
+	next()
+	fix = waitForFix(t, client, 5.1215)
+	if fix.Latitude != 52.0902 || fix.Course != 270.5 {
+		t.Errorf("unexpected fix: %v", fix)
+	}
+
+	// losing the fix
+	next()
+	deadline := time.Now().Add(5 * time.Second)
+	for client.Position() != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("the fix wasn't lost")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	next()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("expected an error when gpsd closes the connection")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Watch() didn't return after gpsd closed the connection")
+	}
+}
+
+func TestClient_Watch_NoGpsd(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	if err := New(address, time.Minute).Watch(); err == nil {
+		t.Errorf("expected an error without gpsd")
+	}
+}
+
+// waitForFix waits until the client has a fix with the longitude
+func waitForFix(t *testing.T, client *Client, longitude float64) *position.Fix {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if fix := client.Position(); fix != nil && fix.Longitude == longitude {
+			return fix
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no fix at longitude %f, got %v", longitude, client.Position())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,197 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// broker is a minimal MQTT 3.1.1 broker for the tests. It delivers everything with QoS 0 and keeps retained messages.
+type broker struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	clients  map[net.Conn][]string
+	retained map[string][]byte
+}
+
+func startBroker(t *testing.T) *broker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &broker{listener: listener, clients: map[net.Conn][]string{}, retained: map[string][]byte{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *broker) url() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+func (b *broker) close() {
+	_ = b.listener.Close()
+	b.drop()
+}
+
+// drop closes the connections of the clients, like a restart of the broker that forgets the sessions
+func (b *broker) drop() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for conn := range b.clients {
+		_ = conn.Close()
+	}
+}
+
+func (b *broker) serve(conn net.Conn) {
+	defer func() {
+		b.mutex.Lock()
+		delete(b.clients, conn)
+		b.mutex.Unlock()
+		_ = conn.Close()
+	}()
+	b.mutex.Lock()
+	b.clients[conn] = nil
+	b.mutex.Unlock()
+
+	reader := bufio.NewReader(conn)
+	for {
+		header, body, err := readPacket(reader)
+		if err != nil {
+			return
+		}
+		switch header >> 4 {
+		case 1: // CONNECT
+			b.write(conn, 0x20, []byte{0, 0})
+		case 3: // PUBLISH
+			length := int(binary.BigEndian.Uint16(body))
+			topic := string(body[2 : 2+length])
+			rest := body[2+length:]
+			if qos := (header >> 1) & 3; qos > 0 {
+				b.write(conn, 0x40, rest[:2])
+				rest = rest[2:]
+			}
+			b.publish(topic, rest, header&1 == 1)
+		case 8: // SUBSCRIBE
+			var filters []string
+			var granted []byte
+			for rest := body[2:]; len(rest) > 0; {
+				length := int(binary.BigEndian.Uint16(rest))
+				filters = append(filters, string(rest[2:2+length]))
+				granted = append(granted, 0)
+				rest = rest[3+length:]
+			}
+			b.mutex.Lock()
+			b.clients[conn] = append(b.clients[conn], filters...)
+			b.mutex.Unlock()
+			b.write(conn, 0x90, append(body[:2:2], granted...))
+			b.mutex.Lock()
+			for topic, payload := range b.retained {
+				for _, filter := range filters {
+					if matches(filter, topic) {
+						b.deliver(conn, topic, payload)
+					}
+				}
+			}
+			b.mutex.Unlock()
+		case 10: // UNSUBSCRIBE
+			b.mutex.Lock()
+			b.clients[conn] = nil
+			b.mutex.Unlock()
+			b.write(conn, 0xb0, body[:2])
+		case 12: // PINGREQ
+			b.write(conn, 0xd0, nil)
+		case 14: // DISCONNECT
+			return
+		}
+	}
+}
+
+func (b *broker) publish(topic string, payload []byte, retain bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if retain {
+		b.retained[topic] = payload
+	}
+	for conn, filters := range b.clients {
+		for _, filter := range filters {
+			if matches(filter, topic) {
+				b.deliver(conn, topic, payload)
+				break
+			}
+		}
+	}
+}
+
+// deliver sends a QoS 0 publish, the mutex must be held
+func (b *broker) deliver(conn net.Conn, topic string, payload []byte) {
+	body := make([]byte, 2, 2+len(topic)+len(payload))
+	binary.BigEndian.PutUint16(body, uint16(len(topic)))
+	body = append(append(body, topic...), payload...)
+	b.write(conn, 0x30, body)
+}
+
+func (b *broker) write(conn net.Conn, header byte, body []byte) {
+	packet := []byte{header}
+	length := len(body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if length == 0 {
+			break
+		}
+	}
+	_, _ = conn.Write(append(packet, body...))
+}
+
+func readPacket(reader *bufio.Reader) (byte, []byte, error) {
+	header, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for {
+		digit, err := reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(reader, body)
+	return header, body, err
+}
+
+// matches checks a topic against a filter with + and # wildcards
+func matches(filter, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topicLevels) || (level != "+" && level != topicLevels[i]) {
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
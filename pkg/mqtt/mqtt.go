@@ -0,0 +1,58 @@
+package mqtt
+
+import (
+	"fmt"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"strings"
+	"time"
+)
+
+// Connect connects to a broker, like tcp://localhost:1883. Username and password are optional.
+// The client reconnects by itself, and calls onConnect, when it isn't nil, after connecting and after every reconnect.
+func Connect(broker, clientID, username, password string, onConnect paho.OnConnectHandler) (paho.Client, error) {
+	options := paho.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(clientID).
+		SetUsername(username).
+		SetPassword(password).
+		SetAutoReconnect(true).
+		SetOnConnectHandler(onConnect).
+		SetConnectTimeout(10 * time.Second)
+	client := paho.NewClient(options)
+	token := client.Connect()
+	token.Wait()
+	if err := token.Error(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// wait waits for a token and returns its error
+func wait(token paho.Token) error {
+	token.Wait()
+	return token.Error()
+}
+
+// stationFromTopic returns the level of the topic that matches the single level wildcard (+) in the filter.
+// ardf/+/bearing matches ardf/fox1/bearing and returns fox1.
+func stationFromTopic(filter, topic string) (string, error) {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	if len(filterLevels) != len(topicLevels) {
+		return "", fmt.Errorf("topic %s does not match %s", topic, filter)
+	}
+	station := ""
+	for i, level := range filterLevels {
+		switch level {
+		case "+":
+			station = topicLevels[i]
+		case topicLevels[i]:
+		default:
+			return "", fmt.Errorf("topic %s does not match %s", topic, filter)
+		}
+	}
+	if station == "" {
+		return "", fmt.Errorf("no station in topic %s", topic)
+	}
+	return station, nil
+}
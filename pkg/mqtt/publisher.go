@@ -0,0 +1,92 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/apex/log"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"time"
+)
+
+// Publisher publishes the estimate to <prefix>/estimate and the crossings to <prefix>/crossings
+type Publisher struct {
+	Database database.Database
+	Client   paho.Client
+	Prefix   string
+	QoS      byte
+	Retain   bool          // let the broker keep the last estimate and crossings for new subscribers
+	Since    time.Duration // use the bearings of this period
+	Interval time.Duration // time between publishing rounds
+}
+
+// NewPublisher returns a publisher for the ardf topic tree
+func NewPublisher(db database.Database, client paho.Client) *Publisher {
+	return &Publisher{
+		Database: db,
+		Client:   client,
+		Prefix:   "ardf",
+		QoS:      1,
+		Retain:   true,
+		Since:    time.Minute,
+		Interval: 5 * time.Second,
+	}
+}
+
+// Start publishes every interval until the context is cancelled, errors are logged
+func (p *Publisher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Publish(); err != nil {
+			log.WithError(err).Warn("Failed to publish estimate and crossings")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Publish sends the estimate, if there is one, and the crossings
+func (p *Publisher) Publish() error {
+	lines, err := p.Database.GetLines(p.Since)
+	if err != nil {
+		return err
+	}
+	var good []*types.Line
+	for _, line := range lines {
+		if !line.Suspect {
+			good = append(good, line)
+		}
+	}
+	if estimate, err := estimator.Estimate(good); err == nil {
+		if err := p.publish("estimate", estimate); err != nil {
+			return err
+		}
+	}
+
+	crossings, err := p.Database.GetCrossings(p.Since)
+	if err != nil {
+		return err
+	}
+	if crossings == nil {
+		crossings = []*types.Crossing{}
+	}
+	if err := p.publish("crossings", crossings); err != nil {
+		return err
+	}
+	log.Debugf("published estimate and %d crossings", len(crossings))
+	return nil
+}
+
+func (p *Publisher) publish(topic string, value interface{}) error {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return wait(p.Client.Publish(p.Prefix+"/"+topic, p.QoS, p.Retain, payload))
+}
@@ -0,0 +1,124 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPublisher_Publish(t *testing.T) {
+	b := startBroker(t)
+	defer b.close()
+
+	now := time.Now()
+	db := &databasetest.Database{
+		Lines: []*types.Line{
+			{Position: types.Position{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99}, Bearing: 0},
+			{Position: types.Position{Timestamp: now, Station: "west", Longitude: 4.98, Latitude: 52}, Bearing: 90},
+		},
+		Crossings: []*types.Crossing{{Longitude: 5, Latitude: 52, Weight: 1, Stations: []string{"south", "west"}, Angle: 90}},
+	}
+	client, err := Connect(b.url(), "publisher", "", "", nil)
+	if err != nil {
+		t.Fatalf("Connect() returned error: %e", err)
+	}
+	defer client.Disconnect(0)
+	if err := NewPublisher(db, client).Publish(); err != nil {
+		t.Fatalf("Publish() returned error: %e", err)
+	}
+
+	// the messages are retained, so a late subscriber still gets them
+	subscriber, err := Connect(b.url(), "subscriber", "", "", nil)
+	if err != nil {
+		t.Fatalf("Connect() returned error: %e", err)
+	}
+	defer subscriber.Disconnect(0)
+	messages := make(chan paho.Message, 2)
+	if err := wait(subscriber.Subscribe("ardf/#", 0, func(_ paho.Client, message paho.Message) {
+		messages <- message
+	})); err != nil {
+		t.Fatalf("Subscribe() returned error: %e", err)
+	}
+
+	received := map[string][]byte{}
+	for len(received) < 2 {
+		select {
+		case message := <-messages:
+			received[message.Topic()] = message.Payload()
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout, received %v", received)
+		}
+	}
+
+	var estimate types.Estimate
+	if err := json.Unmarshal(received["ardf/estimate"], &estimate); err != nil {
+		t.Fatalf("invalid estimate: %e", err)
+	}
+	if math.Abs(estimate.Longitude-5) > 0.001 || math.Abs(estimate.Latitude-52) > 0.001 || estimate.Lines != 2 {
+		t.Errorf("unexpected estimate: %v", estimate)
+	}
+	var crossings []*types.Crossing
+	if err := json.Unmarshal(received["ardf/crossings"], &crossings); err != nil {
+		t.Fatalf("invalid crossings: %e", err)
+	}
+	if len(crossings) != 1 || crossings[0].Angle != 90 {
+		t.Errorf("unexpected crossings: %v", crossings)
+	}
+}
+
+func TestPublisher_Start(t *testing.T) {
+	b := startBroker(t)
+	defer b.close()
+
+	now := time.Now()
+	db := &databasetest.Database{
+		Lines: []*types.Line{
+			{Position: types.Position{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99}, Bearing: 0},
+			{Position: types.Position{Timestamp: now, Station: "west", Longitude: 4.98, Latitude: 52}, Bearing: 90},
+		},
+		LineFailures: 2,
+	}
+	client, err := Connect(b.url(), "publisher", "", "", nil)
+	if err != nil {
+		t.Fatalf("Connect() returned error: %e", err)
+	}
+	defer client.Disconnect(0)
+	messages := make(chan paho.Message, 10)
+	if err := wait(client.Subscribe("ardf/estimate", 0, func(_ paho.Client, message paho.Message) {
+		messages <- message
+	})); err != nil {
+		t.Fatalf("Subscribe() returned error: %e", err)
+	}
+
+	publisher := NewPublisher(db, client)
+	publisher.Interval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- publisher.Start(ctx)
+	}()
+
+	// the estimate is published once the database is back
+	select {
+	case <-messages:
+	case err := <-done:
+		t.Fatalf("Start() returned after a database error: %e", err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for the estimate")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Start() returned %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Start() did not return after the context was cancelled")
+	}
+}
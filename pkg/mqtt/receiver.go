@@ -0,0 +1,142 @@
+package mqtt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/apex/log"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/hsmade/OSM-ARDF/pkg/circular"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/outlier"
+	"github.com/hsmade/OSM-ARDF/pkg/smoothing"
+	"github.com/hsmade/OSM-ARDF/pkg/status"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Receiver stores the bearings that DF units publish on a topic tree like ardf/<station>/bearing.
+// The payload is either a bare bearing in degrees, or a JSON measurement. The station is taken from the topic.
+type Receiver struct {
+	Database database.Database
+	Client   paho.Client
+	Topic    string              // the station is the level matching the + wildcard
+	QoS      byte                // quality of service of the subscription
+	Detector *outlier.Detector   // optional, flags suspect bearings before storing them
+	Smoother *smoothing.Smoother // optional, smoothens the bearings of a station before storing them
+	Status   *status.Recorder    // optional, reports the liveness of the stations
+	mutex    sync.Mutex
+	started  bool // subscribed by Start, so OnConnect subscribes again
+	now      func() time.Time
+}
+
+// NewReceiver returns a receiver for ardf/+/bearing
+func NewReceiver(db database.Database, client paho.Client) *Receiver {
+	return &Receiver{
+		Database: db,
+		Client:   client,
+		Topic:    "ardf/+/bearing",
+		QoS:      1,
+		now:      time.Now,
+	}
+}
+
+// Start subscribes to the topic. Messages are stored in the background until Stop is called.
+// Pass OnConnect to Connect to keep the subscription when the client reconnects.
+func (r *Receiver) Start() error {
+	if err := r.Database.Connect(); err != nil {
+		return err
+	}
+	if err := wait(r.Client.Subscribe(r.Topic, r.QoS, r.handle)); err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.started = true
+	return nil
+}
+
+// OnConnect subscribes again after the client reconnected, as the broker forgets the subscriptions of a clean session
+func (r *Receiver) OnConnect(client paho.Client) {
+	r.mutex.Lock()
+	started := r.started
+	r.mutex.Unlock()
+	if !started {
+		return
+	}
+	if err := wait(client.Subscribe(r.Topic, r.QoS, r.handle)); err != nil {
+		log.WithError(err).WithField("topic", r.Topic).Error("Failed to subscribe again after reconnecting")
+		return
+	}
+	log.WithField("topic", r.Topic).Info("Subscribed again after reconnecting")
+}
+
+// Stop unsubscribes
+func (r *Receiver) Stop() error {
+	r.mutex.Lock()
+	r.started = false
+	r.mutex.Unlock()
+	return wait(r.Client.Unsubscribe(r.Topic))
+}
+
+func (r *Receiver) handle(_ paho.Client, message paho.Message) {
+	m, err := r.parse(message.Topic(), message.Payload())
+	if err != nil {
+		log.WithError(err).WithField("topic", message.Topic()).Error("Failed to parse into measurement")
+		if station, err := stationFromTopic(r.Topic, message.Topic()); err == nil && r.Status != nil {
+			r.Status.Error(station)
+		}
+		return
+	}
+	if r.Status != nil {
+		r.Status.Measurement(m)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.Smoother != nil {
+		if m = r.Smoother.Add(m); m == nil {
+			return
+		}
+	}
+	r.store(m)
+}
+
+// parse maps a payload to a measurement for the station in the topic
+func (r *Receiver) parse(topic string, payload []byte) (*types.Measurement, error) {
+	station, err := stationFromTopic(r.Topic, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &types.Measurement{}
+	payload = bytes.TrimSpace(payload)
+	if bearing, err := strconv.ParseFloat(string(payload), 64); err == nil {
+		m.Bearing = int(math.Round(bearing))
+	} else if err := json.Unmarshal(payload, m); err != nil {
+		return nil, fmt.Errorf("invalid payload %q: %v", payload, err)
+	}
+
+	m.Station = station
+	m.Bearing = int(math.Round(circular.Normalise(float64(m.Bearing)))) % 360
+	if m.Timestamp.IsZero() {
+		m.Timestamp = r.now()
+	}
+	return m, nil
+}
+
+func (r *Receiver) store(m *types.Measurement) {
+	var err error
+	if r.Detector != nil && r.Detector.Check(m) {
+		m.Suspect = true
+		log.WithField("measurement", *m).Warn("Bearing looks like an outlier")
+	}
+	defer log.WithField("measurement", *m).Trace("storing measurement").Stop(&err)
+	err = r.Database.Add(m)
+	if err != nil && r.Status != nil {
+		r.Status.Error(m.Station)
+	}
+}
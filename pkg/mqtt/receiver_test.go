@@ -0,0 +1,132 @@
+package mqtt
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReceiver_parse(t *testing.T) {
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	receiver := NewReceiver(nil, nil)
+	receiver.now = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		topic   string
+		payload string
+		want    *types.Measurement
+		wantErr bool
+	}{
+		{"bare bearing", "ardf/fox1/bearing", " 123.6\n", &types.Measurement{Timestamp: now, Station: "fox1", Bearing: 124}, false},
+		{"negative bearing", "ardf/fox1/bearing", "-10", &types.Measurement{Timestamp: now, Station: "fox1", Bearing: 350}, false},
+		{"json", "ardf/fox2/bearing", `{"bearing": 45, "latitude": 52.1, "longitude": 5.2, "quality": 0.8}`,
+			&types.Measurement{Timestamp: now, Station: "fox2", Bearing: 45, Latitude: 52.1, Longitude: 5.2, Quality: 0.8}, false},
+		{"json with timestamp and station", "ardf/fox2/bearing", `{"Timestamp": "2019-10-01T11:00:00Z", "Station": "other", "Bearing": 360}`,
+			&types.Measurement{Timestamp: now.Add(-time.Hour), Station: "fox2", Bearing: 0}, false},
+		{"invalid payload", "ardf/fox1/bearing", "north", nil, true},
+		{"wrong topic", "ardf/fox1/position", "10", nil, true},
+		{"no station", "ardf//bearing", "10", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := receiver.parse(tt.topic, []byte(tt.payload))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReceiver_Start(t *testing.T) {
+	b := startBroker(t)
+	defer b.close()
+
+	client, err := Connect(b.url(), "receiver", "", "", nil)
+	if err != nil {
+		t.Fatalf("Connect() returned error: %e", err)
+	}
+	defer client.Disconnect(0)
+	db := &databasetest.Database{}
+	receiver := NewReceiver(db, client)
+	if err := receiver.Start(); err != nil {
+		t.Fatalf("Start() returned error: %e", err)
+	}
+
+	box, err := Connect(b.url(), "esp32", "", "", nil)
+	if err != nil {
+		t.Fatalf("Connect() returned error: %e", err)
+	}
+	defer box.Disconnect(0)
+	for _, message := range []struct{ topic, payload string }{
+		{"ardf/fox1/bearing", "90"},
+		{"ardf/fox1/status", "ok"},
+		{"ardf/fox2/bearing", `{"Bearing": 180}`},
+	} {
+		if err := wait(box.Publish(message.topic, 1, false, message.payload)); err != nil {
+			t.Fatalf("Publish() returned error: %e", err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(db.Measurements()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := receiver.Stop(); err != nil {
+		t.Errorf("Stop() returned error: %e", err)
+	}
+
+	stored := db.Measurements()
+	if len(stored) != 2 {
+		t.Fatalf("stored %d measurements, want 2", len(stored))
+	}
+	if stored[0].Station != "fox1" || stored[0].Bearing != 90 || stored[1].Station != "fox2" || stored[1].Bearing != 180 {
+		t.Errorf("unexpected measurements: %v, %v", *stored[0], *stored[1])
+	}
+}
+
+func TestReceiver_Start_Reconnect(t *testing.T) {
+	b := startBroker(t)
+	defer b.close()
+
+	db := &databasetest.Database{}
+	receiver := NewReceiver(db, nil)
+	client, err := Connect(b.url(), "receiver", "", "", receiver.OnConnect)
+	if err != nil {
+		t.Fatalf("Connect() returned error: %e", err)
+	}
+	defer client.Disconnect(0)
+	receiver.Client = client
+	if err := receiver.Start(); err != nil {
+		t.Fatalf("Start() returned error: %e", err)
+	}
+
+	// the broker restarts, and forgets the subscription
+	b.drop()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(db.Measurements()) == 0 && time.Now().Before(deadline) {
+		b.publish("ardf/fox1/bearing", []byte("90"), false)
+		time.Sleep(50 * time.Millisecond)
+	}
+	if len(db.Measurements()) == 0 {
+		t.Fatal("nothing stored after the broker restarted")
+	}
+	if err := receiver.Stop(); err != nil {
+		t.Errorf("Stop() returned error: %e", err)
+	}
+}
+
+func TestStationFromTopic(t *testing.T) {
+	station, err := stationFromTopic("dfboxes/+/ardf/bearing", "dfboxes/esp-12/ardf/bearing")
+	if err != nil || station != "esp-12" {
+		t.Errorf("stationFromTopic() = %s, %v", station, err)
+	}
+	if _, err := stationFromTopic("ardf/+/bearing", "ardf/fox1/bearing/extra"); err == nil {
+		t.Errorf("expected an error for a longer topic")
+	}
+}
@@ -0,0 +1,82 @@
+package nmea
+
+import (
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/circular"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/kellydunn/golang-geo"
+	"math"
+	"time"
+)
+
+// kilometresPerNauticalMile converts distances for the navigation sentences
+const kilometresPerNauticalMile = 1.852
+
+// Waypoint returns a WPL sentence for a waypoint at the estimate
+func Waypoint(estimate *types.Estimate, name string) *Sentence {
+	latitude, north := coordinate(estimate.Latitude, 2, "N", "S")
+	longitude, east := coordinate(estimate.Longitude, 3, "E", "W")
+	return &Sentence{Talker: "GP", Type: "WPL", Fields: []string{latitude, north, longitude, east, name}}
+}
+
+// BearingDistance returns a BWC sentence with the bearing and distance from the station to the estimate
+func BearingDistance(estimate *types.Estimate, station *types.Position, name string, now time.Time) *Sentence {
+	bearing, distance := navigate(station, estimate)
+	latitude, north := coordinate(estimate.Latitude, 2, "N", "S")
+	longitude, east := coordinate(estimate.Longitude, 3, "E", "W")
+	return &Sentence{Talker: "GP", Type: "BWC", Fields: []string{
+		now.UTC().Format("150405.00"),
+		latitude, north, longitude, east,
+		fmt.Sprintf("%.1f", bearing), "T",
+		"", "M",
+		fmt.Sprintf("%.2f", distance), "N",
+		name,
+		"A",
+	}}
+}
+
+// Recommended returns an RMB sentence that navigates the station straight to the estimate.
+// The station is the origin, so there is no cross track error. It has arrived when it is within the radius.
+func Recommended(estimate *types.Estimate, station *types.Position, origin, name string) *Sentence {
+	bearing, distance := navigate(station, estimate)
+	latitude, north := coordinate(estimate.Latitude, 2, "N", "S")
+	longitude, east := coordinate(estimate.Longitude, 3, "E", "W")
+	arrived := "V"
+	if distance*kilometresPerNauticalMile*1000 <= estimate.Radius {
+		arrived = "A"
+	}
+	return &Sentence{Talker: "GP", Type: "RMB", Fields: []string{
+		"A",
+		"0.00", "R",
+		origin, name,
+		latitude, north, longitude, east,
+		fmt.Sprintf("%.2f", distance),
+		fmt.Sprintf("%.1f", bearing),
+		"",
+		arrived,
+		"A",
+	}}
+}
+
+// navigate returns the true bearing in degrees and the distance in nautical miles from the station to the estimate
+func navigate(station *types.Position, estimate *types.Estimate) (float64, float64) {
+	from := geo.NewPoint(station.Latitude, station.Longitude)
+	to := geo.NewPoint(estimate.Latitude, estimate.Longitude)
+	return circular.Normalise(from.BearingTo(to)), from.GreatCircleDistance(to) / kilometresPerNauticalMile
+}
+
+// coordinate formats degrees as degrees and minutes, like 5205.4060, with the hemisphere
+func coordinate(value float64, width int, positive, negative string) (string, string) {
+	hemisphere := positive
+	if value < 0 {
+		hemisphere = negative
+		value = -value
+	}
+	degrees := math.Floor(value)
+	minutes := (value - degrees) * 60
+	if math.Round(minutes*1e4) >= 60*1e4 {
+		degrees++
+		minutes = 0
+	}
+	return fmt.Sprintf("%0*d%07.4f", width, int(degrees), minutes), hemisphere
+}
@@ -0,0 +1,75 @@
+package nmea
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaypoint(t *testing.T) {
+	got := Waypoint(&types.Estimate{Longitude: -5.1214, Latitude: 52.0901}, "FOX").String()
+	if !strings.HasPrefix(got, "$GPWPL,5205.4060,N,00507.2840,W,FOX*") {
+		t.Errorf("Waypoint() got = %s", got)
+	}
+	if _, err := Parse(got); err != nil {
+		t.Errorf("Waypoint() is not valid: %v", err)
+	}
+}
+
+func TestBearingDistance(t *testing.T) {
+	// one degree of latitude north is 60 nautical miles
+	estimate := &types.Estimate{Longitude: 5, Latitude: 53}
+	station := &types.Position{Longitude: 5, Latitude: 52}
+	now := time.Date(2019, 10, 2, 12, 30, 15, 0, time.UTC)
+	got := BearingDistance(estimate, station, "FOX", now)
+	if got.Type != "BWC" || got.Fields[0] != "123015.00" || got.Fields[1] != "5300.0000" || got.Fields[5] != "0.0" ||
+		got.Fields[9] != "60.04" || got.Fields[11] != "FOX" {
+		t.Errorf("BearingDistance() got = %s", got)
+	}
+
+	got = BearingDistance(&types.Estimate{Longitude: 4.9, Latitude: 52}, station, "FOX", now)
+	if got.Fields[5] != "270.0" {
+		t.Errorf("BearingDistance() to the west got bearing %s", got.Fields[5])
+	}
+}
+
+func TestRecommended(t *testing.T) {
+	station := &types.Position{Longitude: 5, Latitude: 52}
+	got := Recommended(&types.Estimate{Longitude: 5.01, Latitude: 52, Radius: 100}, station, "car", "FOX")
+	if got.Type != "RMB" || got.Fields[0] != "A" || got.Fields[3] != "car" || got.Fields[4] != "FOX" ||
+		got.Fields[9] != "0.37" || got.Fields[10] != "90.0" || got.Fields[12] != "V" {
+		t.Errorf("Recommended() got = %s", got)
+	}
+	if len(got.Fields) != 14 {
+		t.Errorf("Recommended() has %d fields, want 14", len(got.Fields))
+	}
+
+	got = Recommended(&types.Estimate{Longitude: 5.001, Latitude: 52, Radius: 100}, station, "car", "FOX")
+	if got.Fields[12] != "A" {
+		t.Errorf("Recommended() within the radius should have arrived: %s", got)
+	}
+}
+
+func TestCoordinate(t *testing.T) {
+	tests := []struct {
+		value      float64
+		width      int
+		want, side string
+	}{
+		{52.0901, 2, "5205.4060", "N"},
+		{-33.8688, 2, "3352.1280", "S"},
+		{5.1214, 3, "00507.2840", "E"},
+		{-151.2093, 3, "15112.5580", "W"},
+		{4.999999999, 3, "00500.0000", "E"},
+	}
+	for _, tt := range tests {
+		got, side := coordinate(tt.value, tt.width, "N", "S")
+		if tt.width == 3 {
+			got, side = coordinate(tt.value, tt.width, "E", "W")
+		}
+		if got != tt.want || side != tt.side {
+			t.Errorf("coordinate(%f) got = %s %s, want %s %s", tt.value, got, side, tt.want, tt.side)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package nmea reads and writes the NMEA 0183 sentences that GPS receivers and navigation software use
+package nmea
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sentence is a parsed sentence, like $GPRMC,...*hh
+type Sentence struct {
+	Talker string // like GP or GN
+	Type   string // like RMC
+	Fields []string
+}
+
+// Parse parses a sentence and checks its checksum, if it has one
+func Parse(line string) (*Sentence, error) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "$") && !strings.HasPrefix(line, "!") {
+		return nil, errors.New("sentence should start with $")
+	}
+	body := line[1:]
+	if i := strings.LastIndex(body, "*"); i >= 0 {
+		checksum, err := strconv.ParseUint(body[i+1:], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid checksum %q", body[i+1:])
+		}
+		body = body[:i]
+		if byte(checksum) != Checksum(body) {
+			return nil, fmt.Errorf("checksum mismatch, got %02X, want %02X", checksum, Checksum(body))
+		}
+	}
+
+	fields := strings.Split(body, ",")
+	if len(fields[0]) != 5 {
+		return nil, fmt.Errorf("invalid address %q", fields[0])
+	}
+	return &Sentence{
+		Talker: fields[0][:2],
+		Type:   fields[0][2:],
+		Fields: fields[1:],
+	}, nil
+}
+
+// Checksum returns the XOR of the characters between $ and *
+func Checksum(body string) byte {
+	var checksum byte
+	for i := 0; i < len(body); i++ {
+		checksum ^= body[i]
+	}
+	return checksum
+}
+
+// String formats the sentence with a checksum, without the line ending
+func (s *Sentence) String() string {
+	body := s.Talker + s.Type
+	if len(s.Fields) > 0 {
+		body += "," + strings.Join(s.Fields, ",")
+	}
+	return fmt.Sprintf("$%s*%02X", body, Checksum(body))
+}
+
+// Field returns a field, or an empty string when the sentence is too short
+func (s *Sentence) Field(i int) string {
+	if i >= len(s.Fields) {
+		return ""
+	}
+	return s.Fields[i]
+}
@@ -0,0 +1,26 @@
+package nmea
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	got, err := Parse("$GPRMC,120000.00,A,5205.4060,N,00507.2840,E,0.0,,021019,,,A*74\r\n")
+	if err != nil {
+		t.Fatalf("Parse() returned error: %e", err)
+	}
+	want := &Sentence{Talker: "GP", Type: "RMC", Fields: []string{"120000.00", "A", "5205.4060", "N", "00507.2840", "E", "0.0", "", "021019", "", "", "A"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() got = %v, want %v", got, want)
+	}
+	if got.String() != "$GPRMC,120000.00,A,5205.4060,N,00507.2840,E,0.0,,021019,,,A*74" {
+		t.Errorf("String() got = %s", got)
+	}
+
+	for _, line := range []string{"GPRMC,1", "$GPRMC,1*00", "$GPRMC,1*zz", "$RMC,1"} {
+		if _, err := Parse(line); err == nil {
+			t.Errorf("expected an error for %q", line)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package nmea
+
+import (
+	"github.com/apex/log"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"net"
+	"sync"
+	"time"
+)
+
+// Server sends the estimate as a waypoint, with navigation from a station to it, to TCP clients and UDP addresses
+type Server struct {
+	Database database.Database
+	Station  string        // navigate from the latest position of this station
+	Waypoint string        // name of the waypoint of the estimate
+	Since    time.Duration // use the bearings of this period
+	Interval time.Duration // time between updates
+	Timeout  time.Duration // how long a write to a TCP client may take before the client is dropped
+	mutex    sync.Mutex
+	clients  map[net.Conn]bool
+	now      func() time.Time
+}
+
+// NewServer returns a server that navigates the station to the FOX waypoint
+func NewServer(db database.Database, station string) *Server {
+	return &Server{
+		Database: db,
+		Station:  station,
+		Waypoint: "FOX",
+		Since:    time.Minute,
+		Interval: time.Second,
+		Timeout:  2 * time.Second,
+		clients:  map[net.Conn]bool{},
+		now:      time.Now,
+	}
+}
+
+// Listen accepts TCP clients on the address in the background, and returns the listener
+func (s *Server) Listen(address string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				log.WithError(err).Warn("Stopped accepting NMEA clients")
+				return
+			}
+			log.Infof("NMEA client %s connected", conn.RemoteAddr())
+			s.add(conn)
+		}
+	}()
+	return listener, nil
+}
+
+// SendTo adds a UDP address, like 192.168.1.10:10110
+func (s *Server) SendTo(address string) error {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return err
+	}
+	s.add(conn)
+	return nil
+}
+
+func (s *Server) add(conn net.Conn) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.clients[conn] = true
+}
+
+// Start publishes every interval, errors are logged
+func (s *Server) Start() {
+	for {
+		if err := s.Publish(); err != nil {
+			log.WithError(err).Warn("Failed to publish NMEA")
+		}
+		time.Sleep(s.Interval)
+	}
+}
+
+// Publish sends the sentences for the current estimate
+func (s *Server) Publish() error {
+	lines, err := s.Database.GetLines(s.Since)
+	if err != nil {
+		return err
+	}
+	var good []*types.Line
+	for _, line := range lines {
+		if !line.Suspect {
+			good = append(good, line)
+		}
+	}
+	estimate, err := estimator.Estimate(good)
+	if err != nil {
+		return err
+	}
+
+	sentences := []*Sentence{Waypoint(estimate, s.Waypoint)}
+	positions, err := s.Database.GetPositions(s.Since)
+	if err != nil {
+		return err
+	}
+	if station := latestPosition(positions, s.Station); station != nil {
+		sentences = append(sentences,
+			Recommended(estimate, station, s.Station, s.Waypoint),
+			BearingDistance(estimate, station, s.Waypoint, s.now()),
+		)
+	} else if s.Station != "" {
+		log.Warnf("no recent position of %s, only sending the waypoint", s.Station)
+	}
+
+	var data []byte
+	for _, sentence := range sentences {
+		data = append(data, sentence.String()+"\r\n"...)
+	}
+	return s.send(data)
+}
+
+// send writes to all clients, and drops the TCP clients that fail or are too slow.
+// The writes happen outside the lock, so a stalled client doesn't hold up new clients.
+func (s *Server) send(data []byte) error {
+	s.mutex.Lock()
+	var conns []net.Conn
+	for conn := range s.clients {
+		conns = append(conns, conn)
+	}
+	s.mutex.Unlock()
+
+	var failed error
+	for _, conn := range conns {
+		_, udp := conn.(*net.UDPConn)
+		if !udp && s.Timeout > 0 {
+			_ = conn.SetWriteDeadline(time.Now().Add(s.Timeout))
+		}
+		if _, err := conn.Write(data); err != nil {
+			if udp {
+				failed = err
+				continue
+			}
+			log.WithError(err).Infof("NMEA client %s disconnected", conn.RemoteAddr())
+			_ = conn.Close()
+			s.mutex.Lock()
+			delete(s.clients, conn)
+			s.mutex.Unlock()
+		}
+	}
+	return failed
+}
+
+// Close disconnects all clients
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for conn := range s.clients {
+		_ = conn.Close()
+		delete(s.clients, conn)
+	}
+	return nil
+}
+
+// latestPosition returns the last position of the station
+func latestPosition(positions []*types.Position, station string) *types.Position {
+	var latest *types.Position
+	for _, position := range positions {
+		if position.Station == station && (latest == nil || position.Timestamp.After(latest.Timestamp)) {
+			latest = position
+		}
+	}
+	return latest
+}
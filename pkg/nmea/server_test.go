@@ -0,0 +1,155 @@
+package nmea
+
+import (
+	"bufio"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer_Publish(t *testing.T) {
+	now := time.Now()
+	db := &databasetest.Database{
+		Positions: []*types.Position{
+			{Timestamp: now.Add(-time.Second), Station: "boat", Longitude: 5, Latitude: 51.9},
+			{Timestamp: now, Station: "boat", Longitude: 5, Latitude: 51.95},
+			{Timestamp: now, Station: "west", Longitude: 4.98, Latitude: 52},
+		},
+		Lines: []*types.Line{
+			{Position: types.Position{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99}, Bearing: 0},
+			{Position: types.Position{Timestamp: now, Station: "west", Longitude: 4.98, Latitude: 52}, Bearing: 90},
+		},
+	}
+	server := NewServer(db, "boat")
+	defer server.Close()
+	listener, err := server.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() returned error: %e", err)
+	}
+	defer listener.Close()
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	if err := server.SendTo(udp.LocalAddr().String()); err != nil {
+		t.Fatalf("SendTo() returned error: %e", err)
+	}
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		server.mutex.Lock()
+		clients := len(server.clients)
+		server.mutex.Unlock()
+		if clients == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("client didn't connect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := server.Publish(); err != nil {
+		t.Fatalf("Publish() returned error: %e", err)
+	}
+
+	_ = client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(client)
+	var got []string
+	for i := 0; i < 3; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(line, "\r\n") {
+			t.Errorf("line should end with CR LF: %q", line)
+		}
+		sentence, err := Parse(line)
+		if err != nil {
+			t.Fatalf("invalid sentence %q: %v", line, err)
+		}
+		got = append(got, sentence.Type)
+		if sentence.Type == "BWC" && (sentence.Fields[5] != "0.0" || sentence.Fields[9] != "3.00") {
+			t.Errorf("unexpected navigation from the boat: %s", sentence)
+		}
+	}
+	if strings.Join(got, ",") != "WPL,RMB,BWC" {
+		t.Errorf("unexpected sentences: %v", got)
+	}
+
+	buffer := make([]byte, 1024)
+	_ = udp.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := udp.ReadFrom(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(buffer[:n]), "\r\n") != 3 {
+		t.Errorf("unexpected datagram: %q", buffer[:n])
+	}
+
+	// without a position of the station only the waypoint is sent
+	server.Station = "unknown"
+	if err := server.Publish(); err != nil {
+		t.Fatalf("Publish() returned error: %e", err)
+	}
+	line, _ := reader.ReadString('\n')
+	if !strings.HasPrefix(line, "$GPWPL,") {
+		t.Errorf("unexpected sentence %q", line)
+	}
+
+	// a client that disconnects is dropped
+	_ = client.Close()
+	for i := 0; i < 10; i++ {
+		_ = server.Publish()
+		server.mutex.Lock()
+		clients := len(server.clients)
+		server.mutex.Unlock()
+		if clients == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("disconnected client wasn't dropped")
+}
+
+func TestServer_send_Stalled(t *testing.T) {
+	server := NewServer(&databasetest.Database{}, "boat")
+	server.Timeout = 10 * time.Millisecond
+	defer server.Close()
+	// nothing reads from the other end of the pipe, so writes block until the deadline
+	stalled, other := net.Pipe()
+	defer other.Close()
+	server.add(stalled)
+
+	done := make(chan error)
+	go func() {
+		done <- server.send([]byte("$GPWPL*00\r\n"))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("send() returned error: %e", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("send() blocked on a stalled client")
+	}
+	if len(server.clients) != 0 {
+		t.Errorf("stalled client wasn't dropped")
+	}
+}
+
+func TestServer_Publish_NoEstimate(t *testing.T) {
+	server := NewServer(&databasetest.Database{}, "boat")
+	if err := server.Publish(); err == nil {
+		t.Errorf("expected an error without bearings")
+	}
+}
@@ -0,0 +1,127 @@
+// Package outlier flags bearings that are likely caused by reflections or multipath
+package outlier
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/circular"
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/kellydunn/golang-geo"
+	"math"
+	"sync"
+	"time"
+)
+
+type Detector struct {
+	Window      time.Duration // how long bearings are remembered
+	History     int           // amount of recent bearings of a station to compare a new bearing with
+	MaxJump     float64       // maximal difference in degrees with the median of the recent bearings of the station
+	MaxResidual float64       // maximal difference in degrees with the bearing to the estimate of the other stations
+	mutex       sync.Mutex
+	recent      map[string][]*types.Line
+}
+
+// New returns a detector with defaults that suit handheld and car mounted DF units
+func New() *Detector {
+	return &Detector{
+		Window:      5 * time.Minute,
+		History:     5,
+		MaxJump:     45,
+		MaxResidual: 30,
+		recent:      map[string][]*types.Line{},
+	}
+}
+
+// Check returns true when the measurement looks like an outlier, and remembers it for the next checks
+func (d *Detector) Check(m *types.Measurement) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	line := &types.Line{
+		Position: types.Position{
+			Timestamp: m.Timestamp,
+			Station:   m.Station,
+			Longitude: m.Longitude,
+			Latitude:  m.Latitude,
+		},
+		Bearing: m.Bearing,
+	}
+	d.expire(line)
+	line.Suspect = d.jumped(line) || d.residual(line)
+	d.recent[m.Station] = append(d.recent[m.Station], line)
+	return line.Suspect
+}
+
+// expire forgets the bearings that are older than the window, counted back from the newest bearing of their station
+// rather than from the clock, so bearings that arrive late or out of order keep their history.
+// Stations that have been silent for longer than the window compared to the newest station are forgotten.
+// The newest bearings are taken from the history itself and the new line, so every remembered bearing expires.
+func (d *Detector) expire(line *types.Line) {
+	newest := map[string]time.Time{line.Station: line.Timestamp}
+	latest := line.Timestamp
+	for station, lines := range d.recent {
+		for _, recent := range lines {
+			if recent.Timestamp.After(newest[station]) {
+				newest[station] = recent.Timestamp
+			}
+		}
+		if newest[station].After(latest) {
+			latest = newest[station]
+		}
+	}
+	for station, lines := range d.recent {
+		if !newest[station].After(latest.Add(-d.Window)) {
+			delete(d.recent, station)
+			continue
+		}
+		start := newest[station].Add(-d.Window)
+		var kept []*types.Line
+		for _, recent := range lines {
+			if recent.Timestamp.After(start) {
+				kept = append(kept, recent)
+			}
+		}
+		d.recent[station] = kept
+	}
+}
+
+// jumped checks the bearing against the recent bearings of the same station
+func (d *Detector) jumped(line *types.Line) bool {
+	lines := d.recent[line.Station]
+	if len(lines) < d.History {
+		return false
+	}
+	var bearings []float64
+	for _, recent := range lines[len(lines)-d.History:] {
+		bearings = append(bearings, float64(recent.Bearing))
+	}
+	return math.Abs(circular.Difference(float64(line.Bearing), circular.Median(bearings))) > d.MaxJump
+}
+
+// residual checks the bearing against the estimate of at least 2 other stations
+func (d *Detector) residual(line *types.Line) bool {
+	var others []*types.Line
+	stations := 0
+	for station, lines := range d.recent {
+		if station == line.Station {
+			continue
+		}
+		count := len(others)
+		for _, other := range lines {
+			if !other.Suspect {
+				others = append(others, other)
+			}
+		}
+		if len(others) > count {
+			stations++
+		}
+	}
+	if stations < 2 {
+		return false
+	}
+	estimate, err := estimator.Estimate(others)
+	if err != nil {
+		return false
+	}
+	expected := geo.NewPoint(line.Latitude, line.Longitude).BearingTo(geo.NewPoint(estimate.Latitude, estimate.Longitude))
+	return math.Abs(circular.Difference(float64(line.Bearing), expected)) > d.MaxResidual
+}
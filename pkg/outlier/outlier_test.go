@@ -0,0 +1,79 @@
+package outlier
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"testing"
+	"time"
+)
+
+func TestDetector_Check_Jump(t *testing.T) {
+	d := New()
+	start := time.Now()
+	bearings := []int{10, 12, 11, 9, 10, 190, 11, 13}
+	want := []bool{false, false, false, false, false, true, false, false}
+	for i, bearing := range bearings {
+		m := &types.Measurement{Timestamp: start.Add(time.Duration(i) * time.Second), Station: "car1", Longitude: 5, Latitude: 52, Bearing: bearing}
+		if got := d.Check(m); got != want[i] {
+			t.Errorf("Check(%d) = %v, want %v", bearing, got, want[i])
+		}
+	}
+}
+
+func TestDetector_Check_Residual(t *testing.T) {
+	d := New()
+	now := time.Now()
+	// the transmitter is at 5, 52
+	d.Check(&types.Measurement{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99, Bearing: 0})
+	d.Check(&types.Measurement{Timestamp: now, Station: "west", Longitude: 4.98, Latitude: 52, Bearing: 90})
+
+	if d.Check(&types.Measurement{Timestamp: now, Station: "north", Longitude: 5, Latitude: 52.01, Bearing: 175}) {
+		t.Errorf("bearing towards the estimate flagged as suspect")
+	}
+	if !d.Check(&types.Measurement{Timestamp: now, Station: "east", Longitude: 5.02, Latitude: 52, Bearing: 200}) {
+		t.Errorf("bearing away from the estimate not flagged as suspect")
+	}
+}
+
+func TestDetector_Check_Expire(t *testing.T) {
+	d := New()
+	start := time.Now()
+	for i := 0; i < d.History; i++ {
+		d.Check(&types.Measurement{Timestamp: start, Station: "car1", Bearing: 10})
+	}
+	if d.Check(&types.Measurement{Timestamp: start.Add(d.Window + time.Second), Station: "car1", Bearing: 190}) {
+		t.Errorf("bearing compared with expired bearings")
+	}
+}
+
+func TestDetector_Check_OutOfOrder(t *testing.T) {
+	d := New()
+	start := time.Now().Add(-time.Hour)
+	// bearings that arrive out of order keep the history of the station
+	timestamps := []time.Duration{4, 0, 3, 1, 2}
+	for _, offset := range timestamps {
+		d.Check(&types.Measurement{Timestamp: start.Add(offset * time.Second), Station: "car1", Bearing: 10})
+	}
+	d.Check(&types.Measurement{Timestamp: start.Add(time.Second), Station: "car2", Bearing: 10})
+	if !d.Check(&types.Measurement{Timestamp: start.Add(5 * time.Second), Station: "car1", Bearing: 190}) {
+		t.Errorf("bearing not compared with the bearings that arrived out of order")
+	}
+	if len(d.recent["car2"]) != 1 {
+		t.Errorf("bearing of another station expired: %v", d.recent["car2"])
+	}
+}
+
+func TestDetector_Check_Returning(t *testing.T) {
+	d := New()
+	start := time.Now().Add(-time.Hour)
+	d.Check(&types.Measurement{Timestamp: start, Station: "car1", Bearing: 10})
+	d.Check(&types.Measurement{Timestamp: start.Add(2 * d.Window), Station: "car2", Bearing: 10})
+	// a late bearing of a station that was forgotten is remembered, and expires again
+	d.Check(&types.Measurement{Timestamp: start.Add(time.Second), Station: "car1", Bearing: 10})
+	if len(d.recent["car1"]) != 1 {
+		t.Fatalf("expected the late bearing to be remembered, got %v", d.recent["car1"])
+	}
+	d.Check(&types.Measurement{Timestamp: start.Add(2*d.Window + time.Second), Station: "car2", Bearing: 10})
+	if _, found := d.recent["car1"]; found {
+		t.Errorf("late bearing never expired: %v", d.recent["car1"])
+	}
+}
@@ -0,0 +1,146 @@
+package position
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/hsmade/OSM-ARDF/pkg/nmea"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NMEA provides the position from the RMC and GGA sentences of a GPS
+type NMEA struct {
+	*Latest
+}
+
+// NewNMEA returns a provider for fixes up to maxAge old. Start it with Read.
+func NewNMEA(maxAge time.Duration) *NMEA {
+	return &NMEA{NewLatest(maxAge)}
+}
+
+// Read keeps the latest valid fix of an NMEA stream, until the stream ends
+func (n *NMEA) Read(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(ScanLines)
+	for scanner.Scan() {
+		sentence, err := nmea.Parse(scanner.Text())
+		if err != nil {
+			log.WithError(err).Debug("Skipping NMEA sentence")
+			continue
+		}
+		if sentence.Type != "RMC" && sentence.Type != "GGA" {
+			continue
+		}
+		fix, valid, err := parseFix(sentence)
+		if err != nil {
+			log.WithError(err).Warn("Failed to parse fix")
+			continue
+		}
+		if !valid {
+			n.Lost()
+			continue
+		}
+		n.Update(fix)
+	}
+	return scanner.Err()
+}
+
+// parseFix reads a fix from an RMC or GGA sentence, and whether the GPS considers it valid.
+// GGA has no date, so today (UTC) is used.
+func parseFix(s *nmea.Sentence) (*Fix, bool, error) {
+	switch s.Type {
+	case "RMC":
+		return parseRMC(s)
+	case "GGA":
+		return parseGGA(s)
+	}
+	return nil, false, fmt.Errorf("%s does not contain a fix", s.Type)
+}
+
+// RMC: time, status, latitude, N/S, longitude, E/W, speed, course, date, ...
+func parseRMC(s *nmea.Sentence) (fix *Fix, valid bool, err error) {
+	fix = &Fix{}
+	if fix.Timestamp, err = parseTime(s.Field(8), s.Field(0)); err != nil {
+		return nil, false, err
+	}
+	if fix.Latitude, err = parseCoordinate(s.Field(2), s.Field(3)); err != nil {
+		return nil, false, err
+	}
+	if fix.Longitude, err = parseCoordinate(s.Field(4), s.Field(5)); err != nil {
+		return nil, false, err
+	}
+	if value := s.Field(7); value != "" {
+		if fix.Course, err = strconv.ParseFloat(value, 64); err != nil {
+			return nil, false, fmt.Errorf("invalid course %q", value)
+		}
+		fix.HasCourse = true
+	}
+	return fix, s.Field(1) == "A", nil
+}
+
+// GGA: time, latitude, N/S, longitude, E/W, quality, ...
+func parseGGA(s *nmea.Sentence) (fix *Fix, valid bool, err error) {
+	fix = &Fix{}
+	if fix.Timestamp, err = parseTime(time.Now().UTC().Format("020106"), s.Field(0)); err != nil {
+		return nil, false, err
+	}
+	if fix.Latitude, err = parseCoordinate(s.Field(1), s.Field(2)); err != nil {
+		return nil, false, err
+	}
+	if fix.Longitude, err = parseCoordinate(s.Field(3), s.Field(4)); err != nil {
+		return nil, false, err
+	}
+	return fix, s.Field(5) != "" && s.Field(5) != "0", nil
+}
+
+// parseTime parses a date like 021019 and a time like 120000.00
+func parseTime(date, clock string) (time.Time, error) {
+	if len(date) != 6 || len(clock) < 6 {
+		return time.Time{}, fmt.Errorf("invalid date %q or time %q", date, clock)
+	}
+	layout := "020106150405"
+	if len(clock) > 6 {
+		layout += "." + strings.Repeat("0", len(clock)-7)
+	}
+	t, err := time.Parse(layout, date+clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q or time %q", date, clock)
+	}
+	return t, nil
+}
+
+// parseCoordinate parses degrees and minutes like 5205.4060 with a hemisphere
+func parseCoordinate(value, hemisphere string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid coordinate %q", value)
+	}
+	degrees := math.Floor(number / 100)
+	coordinate := degrees + (number-degrees*100)/60
+	switch hemisphere {
+	case "N", "E":
+		return coordinate, nil
+	case "S", "W":
+		return -coordinate, nil
+	}
+	return 0, fmt.Errorf("invalid hemisphere %q", hemisphere)
+}
+
+// ScanLines splits on CR, LF or both, as the line endings of serial devices vary
+func ScanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
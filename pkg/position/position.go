@@ -0,0 +1,65 @@
+// Package position provides the current position of a station, for receivers whose DF unit doesn't report it
+package position
+
+import (
+	"sync"
+	"time"
+)
+
+// Fix is a position of a station, with its course when it moves
+type Fix struct {
+	Timestamp time.Time
+	Longitude float64
+	Latitude  float64
+	Course    float64 // true course over ground in degrees
+	HasCourse bool
+}
+
+// Provider gives the current position of a station
+type Provider interface {
+	// Position returns nil when there is no recent fix
+	Position() *Fix
+}
+
+// Latest keeps the latest fix, and forgets it when it gets older than MaxAge
+type Latest struct {
+	MaxAge   time.Duration
+	mutex    sync.Mutex
+	fix      *Fix
+	received time.Time
+	now      func() time.Time
+}
+
+// NewLatest returns a provider for fixes up to maxAge old
+func NewLatest(maxAge time.Duration) *Latest {
+	return &Latest{MaxAge: maxAge, now: time.Now}
+}
+
+// Update sets the latest fix. A fix without a course keeps the course of the previous fix.
+func (l *Latest) Update(fix *Fix) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.fix != nil && !fix.HasCourse {
+		fix.Course, fix.HasCourse = l.fix.Course, l.fix.HasCourse
+	}
+	l.fix = fix
+	l.received = l.now()
+}
+
+// Lost forgets the latest fix
+func (l *Latest) Lost() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.fix = nil
+}
+
+// Position returns a copy of the latest fix, if it is recent enough
+func (l *Latest) Position() *Fix {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.fix == nil || l.now().Sub(l.received) > l.MaxAge {
+		return nil
+	}
+	fix := *l.fix
+	return &fix
+}
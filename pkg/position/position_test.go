@@ -0,0 +1,103 @@
+package position
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/nmea"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatest(t *testing.T) {
+	now := time.Date(2019, 10, 2, 12, 0, 0, 0, time.UTC)
+	latest := NewLatest(5 * time.Second)
+	latest.now = func() time.Time { return now }
+
+	if fix := latest.Position(); fix != nil {
+		t.Errorf("expected no fix, got %v", fix)
+	}
+	latest.Update(&Fix{Longitude: 5, Latitude: 52, Course: 90, HasCourse: true})
+	latest.Update(&Fix{Longitude: 5.1, Latitude: 52})
+	fix := latest.Position()
+	if fix == nil || fix.Longitude != 5.1 || !fix.HasCourse || fix.Course != 90 {
+		t.Errorf("unexpected fix: %v", fix)
+	}
+
+	now = now.Add(6 * time.Second)
+	if fix := latest.Position(); fix != nil {
+		t.Errorf("expected no fix after the maximum age, got %v", fix)
+	}
+	latest.Update(&Fix{Longitude: 5.2, Latitude: 52})
+	latest.Lost()
+	if fix := latest.Position(); fix != nil {
+		t.Errorf("expected no fix after losing it, got %v", fix)
+	}
+}
+
+func TestNMEA_Read(t *testing.T) {
+	provider := NewNMEA(time.Minute)
+	err := provider.Read(strings.NewReader("$GPGSV,3,1,11*00\r" +
+		"$GPRMC,120000.00,A,5205.4060,N,00507.2840,E,12.5,270.0,021019,,,A*69\r\n" +
+		"$GPGGA,120001,5205.4000,N,00507.2000,E,1,08,0.9,10.0,M,46.9,M,,\n"))
+	if err != nil {
+		t.Fatalf("Read() returned error: %e", err)
+	}
+	fix := provider.Position()
+	if fix == nil || math.Abs(fix.Latitude-52.09) > 1e-9 || math.Abs(fix.Longitude-5.12) > 1e-9 || fix.Course != 270 {
+		t.Errorf("unexpected fix: %v", fix)
+	}
+
+	_ = provider.Read(strings.NewReader("$GPRMC,120002.00,V,,,,,,,021019,,,N\n"))
+	if fix := provider.Position(); fix != nil {
+		t.Errorf("expected the fix to be lost, got %v", fix)
+	}
+}
+
+func TestParseFix(t *testing.T) {
+	tests := []struct {
+		line      string
+		want      *Fix
+		wantValid bool
+		wantErr   bool
+	}{
+		{"$GPRMC,120000.00,A,5205.4060,N,00507.2840,E,12.5,275.3,021019,,,A", &Fix{
+			Timestamp: time.Date(2019, 10, 2, 12, 0, 0, 0, time.UTC),
+			Longitude: 5.1214, Latitude: 52.0901, Course: 275.3, HasCourse: true,
+		}, true, false},
+		{"$GNRMC,235959,V,3352.1280,S,15112.5600,W,,,311219,,,N", &Fix{
+			Timestamp: time.Date(2019, 12, 31, 23, 59, 59, 0, time.UTC),
+			Longitude: -151.2093333, Latitude: -33.8688,
+		}, false, false},
+		{"$GPGGA,120000,5205.4060,N,00507.2840,E,1,08,0.9,10.0,M,46.9,M,,", &Fix{
+			Longitude: 5.1214, Latitude: 52.0901,
+		}, true, false},
+		{"$GPGSV,3,1,11", nil, false, true},
+		{"$GPRMC,120000,A,5205.4060,X,00507.2840,E,,,021019", nil, false, true},
+		{"$GPRMC,120000,A,5205.4060,N,00507.2840,E,,,", nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			sentence, err := nmea.Parse(tt.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, valid, err := parseFix(sentence)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if valid != tt.wantValid || got.Course != tt.want.Course || got.HasCourse != tt.want.HasCourse ||
+				math.Abs(got.Longitude-tt.want.Longitude) > 1e-6 || math.Abs(got.Latitude-tt.want.Latitude) > 1e-6 {
+				t.Errorf("parseFix() got = %v, want %v", got, tt.want)
+			}
+			if !tt.want.Timestamp.IsZero() && !got.Timestamp.Equal(tt.want.Timestamp) {
+				t.Errorf("parseFix() got time %v, want %v", got.Timestamp, tt.want.Timestamp)
+			}
+			if tt.want.Timestamp.IsZero() && got.Timestamp.Format("150405") != "120000" {
+				t.Errorf("parseFix() got time %v", got.Timestamp)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+// Package positiontest provides position providers for tests
+package positiontest
+
+import "github.com/hsmade/OSM-ARDF/pkg/position"
+
+// Fixed is a position provider that always has the same fix
+type Fixed position.Fix
+
+func (f Fixed) Position() *position.Fix {
+	fix := position.Fix(f)
+	return &fix
+}
@@ -0,0 +1,59 @@
+// Package quality judges how well the geometry of a crossing pins down the transmitter
+package quality
+
+import (
+	"errors"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/kellydunn/golang-geo"
+	"math"
+)
+
+type Thresholds struct {
+	MinAngle    float64 // minimal angle between the lines, in degrees
+	MaxDilution float64 // maximal position error in metres per degree of bearing error, 0 to disable
+}
+
+// New returns the default thresholds
+func New() *Thresholds {
+	return &Thresholds{
+		MinAngle:    10,
+		MaxDilution: 500,
+	}
+}
+
+// Assess fills in the geometry quality of a crossing of lines a and b.
+// It returns an error when the lines are parallel, as the crossing then has no defined position.
+func Assess(crossing *types.Crossing, a, b *types.Line) error {
+	point := geo.NewPoint(crossing.Latitude, crossing.Longitude)
+	crossing.Distances = map[string]float64{}
+	var squaredDistances float64
+	for _, line := range []*types.Line{a, b} {
+		station := geo.NewPoint(line.Latitude, line.Longitude)
+		distance := station.GreatCircleDistance(point) * 1000
+		crossing.Distances[line.Station] = distance
+		squaredDistances += distance * distance
+	}
+
+	crossing.Angle = math.Abs(math.Remainder(float64(a.Bearing-b.Bearing), 180))
+	sin := math.Sin(crossing.Angle * math.Pi / 180)
+	if sin <= 0 {
+		return errors.New("lines of " + a.Station + " and " + b.Station + " are parallel")
+	}
+	crossing.Dilution = math.Sqrt(squaredDistances) * math.Pi / 180 / sin
+	return nil
+}
+
+// Filter returns the crossings that meet the thresholds
+func (t *Thresholds) Filter(crossings []*types.Crossing) []*types.Crossing {
+	var result []*types.Crossing
+	for _, crossing := range crossings {
+		if crossing.Angle < t.MinAngle {
+			continue
+		}
+		if t.MaxDilution > 0 && crossing.Dilution > t.MaxDilution {
+			continue
+		}
+		result = append(result, crossing)
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+package quality
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"testing"
+)
+
+func TestAssess(t *testing.T) {
+	south := &types.Line{Position: types.Position{Station: "south", Longitude: 5, Latitude: 51.99}, Bearing: 0}
+	west := &types.Line{Position: types.Position{Station: "west", Longitude: 4.98, Latitude: 52}, Bearing: 90}
+	southEast := &types.Line{Position: types.Position{Station: "south_east", Longitude: 5.001, Latitude: 51.99}, Bearing: 358}
+
+	tests := []struct {
+		name         string
+		a, b         *types.Line
+		wantAngle    float64
+		wantDilution float64
+		wantErr      bool
+	}{
+		{
+			name:         "perpendicular",
+			a:            south,
+			b:            west,
+			wantAngle:    90,
+			wantDilution: math.Hypot(1112, 1370) * math.Pi / 180,
+		},
+		{
+			name:         "near parallel",
+			a:            south,
+			b:            southEast,
+			wantAngle:    2,
+			wantDilution: math.Hypot(1112, 1112) * math.Pi / 180 / math.Sin(2*math.Pi/180),
+		},
+		{
+			name:    "parallel",
+			a:       south,
+			b:       &types.Line{Position: types.Position{Station: "south_east", Longitude: 5.001, Latitude: 51.99}, Bearing: 180},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crossing := &types.Crossing{Longitude: 5, Latitude: 52}
+			err := Assess(crossing, tt.a, tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Assess() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if crossing.Angle != tt.wantAngle {
+				t.Errorf("expected angle %f, got %f", tt.wantAngle, crossing.Angle)
+			}
+			if math.Abs(crossing.Dilution-tt.wantDilution)/tt.wantDilution > 0.01 {
+				t.Errorf("expected dilution %f, got %f", tt.wantDilution, crossing.Dilution)
+			}
+			if len(crossing.Distances) != 2 || math.Abs(crossing.Distances["south"]-1112) > 1 {
+				t.Errorf("unexpected distances: %v", crossing.Distances)
+			}
+		})
+	}
+}
+
+func TestThresholds_Filter(t *testing.T) {
+	crossings := []*types.Crossing{
+		{Angle: 90, Dilution: 30},
+		{Angle: 5, Dilution: 30},
+		{Angle: 45, Dilution: 1000},
+	}
+	if got := New().Filter(crossings); len(got) != 1 || got[0] != crossings[0] {
+		t.Errorf("Filter() = %v, want only the first crossing", got)
+	}
+	if got := (&Thresholds{}).Filter(crossings); len(got) != 3 {
+		t.Errorf("Filter() without thresholds dropped crossings: %v", got)
+	}
+}
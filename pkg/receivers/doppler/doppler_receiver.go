@@ -0,0 +1,109 @@
+package doppler
+
+import (
+	"bufio"
+	"github.com/apex/log"
+	"github.com/hsmade/OSM-ARDF/pkg/circular"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/outlier"
+	"github.com/hsmade/OSM-ARDF/pkg/position"
+	"github.com/hsmade/OSM-ARDF/pkg/status"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"io"
+	"math"
+	"time"
+)
+
+// Receiver combines the bearings of a Doppler DF unit with the current position of the station
+type Receiver struct {
+	Database database.Database
+	Station  string
+	Format   Format
+	Position position.Provider // optional, without a fix the database locates the bearing from the track
+	Relative bool              // the antenna is on a vehicle and the bearing is relative to its course
+	Detector *outlier.Detector // optional, flags suspect bearings before storing them
+	Status   *status.Recorder  // optional, reports the liveness of the station
+	now      func() time.Time
+}
+
+// NewReceiver returns a receiver for the station
+func NewReceiver(db database.Database, station string, format Format) *Receiver {
+	return &Receiver{
+		Database: db,
+		Station:  station,
+		Format:   format,
+		now:      time.Now,
+	}
+}
+
+// Start stores bearings until the DF unit stops
+func (r *Receiver) Start(bearings io.Reader) error {
+	if err := r.Database.Connect(); err != nil {
+		return err
+	}
+	return r.ReadBearings(bearings)
+}
+
+// ReadBearings stores the bearings that the DF unit writes
+func (r *Receiver) ReadBearings(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(position.ScanLines)
+	for scanner.Scan() {
+		bearing, err := r.Format.Parse(scanner.Text())
+		if err != nil {
+			log.WithError(err).Warn("Failed to parse bearing")
+			if r.Status != nil {
+				r.Status.Error(r.Station)
+			}
+			continue
+		}
+		if bearing == nil {
+			continue
+		}
+		m := r.measurement(bearing)
+		if m == nil {
+			continue
+		}
+		if r.Status != nil {
+			r.Status.Measurement(m)
+		}
+		if r.Detector != nil && r.Detector.Check(m) {
+			m.Suspect = true
+			log.WithField("measurement", *m).Warn("Bearing looks like an outlier")
+		}
+		if err := r.Database.Add(m); err != nil {
+			log.WithError(err).WithField("measurement", *m).Error("Failed to store measurement")
+			if r.Status != nil {
+				r.Status.Error(r.Station)
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// measurement combines a bearing with the current position. Relative bearings are dropped when the course is unknown.
+func (r *Receiver) measurement(bearing *Bearing) *types.Measurement {
+	m := &types.Measurement{
+		Timestamp: r.now(),
+		Station:   r.Station,
+		Quality:   bearing.Quality,
+	}
+	var fix *position.Fix
+	if r.Position != nil {
+		fix = r.Position.Position()
+	}
+	if fix != nil {
+		m.Longitude, m.Latitude = fix.Longitude, fix.Latitude
+	}
+
+	angle := bearing.Bearing
+	if r.Relative {
+		if fix == nil || !fix.HasCourse {
+			log.Warn("Dropping relative bearing without a known course")
+			return nil
+		}
+		angle += fix.Course
+	}
+	m.Bearing = int(math.Round(circular.Normalise(angle))) % 360
+	return m
+}
@@ -0,0 +1,121 @@
+//go:build !windows
+// +build !windows
+
+package doppler
+
+import (
+	"github.com/creack/pty"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/position"
+	"github.com/hsmade/OSM-ARDF/pkg/position/positiontest"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestReceiver_pty feeds the receiver through pty pairs, like a DF unit and a GPS on serial ports.
+// Closing the controlling side ends the stream, as a blocking read on the port can't be interrupted.
+func TestReceiver_pty(t *testing.T) {
+	gpsPtmx, gpsTty, err := pty.Open()
+	if err != nil {
+		t.Skipf("no pty available: %v", err)
+	}
+	defer gpsTty.Close()
+	defer gpsPtmx.Close()
+	dfPtmx, dfTty, err := pty.Open()
+	if err != nil {
+		t.Skipf("no pty available: %v", err)
+	}
+	defer dfTty.Close()
+	defer dfPtmx.Close()
+
+	gps, err := Open(gpsTty.Name(), 4800)
+	if err != nil {
+		t.Fatalf("Open() returned error: %e", err)
+	}
+	df, err := Open(dfTty.Name(), 9600)
+	if err != nil {
+		t.Fatalf("Open() returned error: %e", err)
+	}
+
+	db := &databasetest.Database{}
+	gpsProvider := position.NewNMEA(5 * time.Second)
+	go func() {
+		_ = gpsProvider.Read(gps)
+	}()
+	receiver := NewReceiver(db, "car", Agrelo{})
+	receiver.Position = gpsProvider
+	receiver.Relative = true
+	done := make(chan error)
+	go func() {
+		done <- receiver.Start(df)
+	}()
+
+	// a bearing without a fix is dropped, as its course is unknown
+	write(t, dfPtmx, "%090/5\r")
+	write(t, gpsPtmx, "$GPGSV,3,1,11*00\r\n$GPRMC,120000.00,A,5205.4060,N,00507.2840,E,12.5,270.0,021019,,,A*69\r\n")
+	waitFor(t, func() bool {
+		return gpsProvider.Position() != nil
+	})
+	write(t, dfPtmx, "%090/5\r%garbage\r%045/7\r")
+	waitFor(t, func() bool {
+		return len(db.Measurements()) == 2
+	})
+
+	_ = dfPtmx.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start() didn't return at the end of the stream")
+	}
+	_ = df.Close()
+	_ = gpsPtmx.Close()
+	_ = gps.Close()
+
+	stored := db.Measurements()
+	first, second := stored[0], stored[1]
+	if first.Station != "car" || first.Bearing != 0 || first.Quality != 5 || first.Latitude < 52.09 || first.Longitude < 5.12 {
+		t.Errorf("unexpected first measurement: %v", *first)
+	}
+	if second.Bearing != 315 || second.Quality != 7 {
+		t.Errorf("unexpected second measurement: %v", *second)
+	}
+}
+
+func TestReceiver_measurement(t *testing.T) {
+	now := time.Date(2019, 10, 2, 12, 0, 0, 0, time.UTC)
+	receiver := NewReceiver(nil, "fixed", Agrelo{})
+	receiver.now = func() time.Time { return now }
+
+	m := receiver.measurement(&Bearing{Bearing: 359.6})
+	if m.Bearing != 0 || m.Longitude != 0 || m.Latitude != 0 || !m.Timestamp.Equal(now) {
+		t.Errorf("unexpected measurement without a fix: %v", *m)
+	}
+
+	// relative bearings need a course
+	receiver.Relative = true
+	if m := receiver.measurement(&Bearing{Bearing: 300}); m != nil {
+		t.Errorf("expected no measurement without a course, got %v", *m)
+	}
+	receiver.Position = positiontest.Fixed{Longitude: 5, Latitude: 52, Course: 90.4, HasCourse: true}
+	m = receiver.measurement(&Bearing{Bearing: 300})
+	if m.Bearing != 30 || m.Longitude != 5 || m.Latitude != 52 {
+		t.Errorf("unexpected measurement with a fix: %v", *m)
+	}
+}
+
+func write(t *testing.T, file *os.File, data string) {
+	if _, err := file.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
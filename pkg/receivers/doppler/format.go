@@ -0,0 +1,121 @@
+package doppler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Bearing is a bearing as read from a DF unit
+type Bearing struct {
+	Bearing float64
+	Quality float64
+}
+
+// Format parses the lines that a DF unit writes to its serial port
+type Format interface {
+	// Parse returns nil without an error for lines that don't contain a bearing
+	Parse(line string) (*Bearing, error)
+}
+
+var formats = map[string]Format{}
+
+// Register makes a format available by name
+func Register(name string, format Format) {
+	formats[name] = format
+}
+
+// Lookup returns a registered format
+func Lookup(name string) (Format, error) {
+	format, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %s, use one of %s", name, strings.Join(Formats(), ", "))
+	}
+	return format, nil
+}
+
+// Formats returns the names of the registered formats
+func Formats() []string {
+	var names []string
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("agrelo", Agrelo{})
+}
+
+// Agrelo is the %bbb/q format of the Agrelo DFjr, which most Doppler units like the
+// N7LUF and DF2020 can emit too. The quality is 0 - 9.
+type Agrelo struct{}
+
+var agreloPattern = regexp.MustCompile(`^%(\d{1,3})/(\d)$`)
+
+func (Agrelo) Parse(line string) (*Bearing, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, nil
+	}
+	match := agreloPattern.FindStringSubmatch(line)
+	if match == nil {
+		return nil, fmt.Errorf("not an Agrelo bearing: %q", line)
+	}
+	bearing, _ := strconv.Atoi(match[1])
+	quality, _ := strconv.Atoi(match[2])
+	if bearing > 360 {
+		return nil, fmt.Errorf("bearing out of range: %d", bearing)
+	}
+	return &Bearing{Bearing: float64(bearing % 360), Quality: float64(quality)}, nil
+}
+
+// Pattern is a format defined by a regular expression with the named groups bearing and, optionally, quality.
+// Lines that don't match are ignored.
+type Pattern struct {
+	pattern *regexp.Regexp
+}
+
+// NewPattern compiles a pattern, like `^B(?P<bearing>\d+) Q(?P<quality>\d+)$`
+func NewPattern(expression string) (*Pattern, error) {
+	pattern, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	if group(pattern, "bearing") < 0 {
+		return nil, errors.New("pattern should have a group named bearing")
+	}
+	return &Pattern{pattern}, nil
+}
+
+func (p *Pattern) Parse(line string) (*Bearing, error) {
+	match := p.pattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return nil, nil
+	}
+	bearing, err := strconv.ParseFloat(match[group(p.pattern, "bearing")], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bearing: %v", err)
+	}
+	result := &Bearing{Bearing: bearing}
+	if i := group(p.pattern, "quality"); i >= 0 && match[i] != "" {
+		if result.Quality, err = strconv.ParseFloat(match[i], 64); err != nil {
+			return nil, fmt.Errorf("invalid quality: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// group returns the index of a named group, or -1
+func group(pattern *regexp.Regexp, name string) int {
+	for i, groupName := range pattern.SubexpNames() {
+		if groupName == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,65 @@
+package doppler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAgrelo_Parse(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    *Bearing
+		wantErr bool
+	}{
+		{"%123/7", &Bearing{Bearing: 123, Quality: 7}, false},
+		{" %5/0\r", &Bearing{Bearing: 5, Quality: 0}, false},
+		{"%360/9", &Bearing{Bearing: 0, Quality: 9}, false},
+		{"", nil, false},
+		{"%361/9", nil, true},
+		{"%123/", nil, true},
+		{"123/7", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := Agrelo{}.Parse(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPattern_Parse(t *testing.T) {
+	pattern, err := NewPattern(`^B(?P<bearing>\d+(\.\d+)?)( Q(?P<quality>\d+))?$`)
+	if err != nil {
+		t.Fatalf("NewPattern() returned error: %e", err)
+	}
+	got, err := pattern.Parse("B123.5 Q8")
+	if err != nil || !reflect.DeepEqual(got, &Bearing{Bearing: 123.5, Quality: 8}) {
+		t.Errorf("Parse() got = %v, %v", got, err)
+	}
+	got, err = pattern.Parse("B90")
+	if err != nil || !reflect.DeepEqual(got, &Bearing{Bearing: 90}) {
+		t.Errorf("Parse() got = %v, %v", got, err)
+	}
+	got, err = pattern.Parse("status ok")
+	if err != nil || got != nil {
+		t.Errorf("Parse() got = %v, %v for a line without a bearing", got, err)
+	}
+
+	if _, err := NewPattern(`^(\d+)$`); err == nil {
+		t.Errorf("expected an error for a pattern without a bearing group")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if format, err := Lookup("agrelo"); err != nil || format != (Agrelo{}) {
+		t.Errorf("Lookup(agrelo) got = %v, %v", format, err)
+	}
+	if _, err := Lookup("unknown"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,11 @@
+package doppler
+
+import (
+	"github.com/tarm/serial"
+	"io"
+)
+
+// Open opens a serial port, or a pseudo terminal, with 8 data bits, no parity and one stop bit
+func Open(name string, baud int) (io.ReadCloser, error) {
+	return serial.OpenPort(&serial.Config{Name: name, Baud: baud})
+}
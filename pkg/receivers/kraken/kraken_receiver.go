@@ -0,0 +1,122 @@
+package kraken
+
+import (
+	"fmt"
+	"github.com/apex/log"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/outlier"
+	"github.com/hsmade/OSM-ARDF/pkg/position"
+	"github.com/hsmade/OSM-ARDF/pkg/status"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Receiver polls the results of a KrakenSDR and stores them
+type Receiver struct {
+	Database database.Database
+	URL      string            // like http://krakensdr:8081/DOA_value.html
+	Station  string            // overrides the station id of the KrakenSDR when set
+	Relative bool              // the array is mounted on a vehicle, and the DoA is relative to its heading
+	Position position.Provider // optional, overrides the position and heading of the KrakenSDR when it has a fix
+	Interval time.Duration     // time between polls
+	Detector *outlier.Detector // optional, flags suspect bearings before storing them
+	Status   *status.Recorder  // optional, reports the liveness of the station
+	client   *http.Client
+	last     time.Time
+	station  string // station id of the last result, to report failed polls for
+}
+
+// NewReceiver returns a receiver that polls the url every second
+func NewReceiver(db database.Database, url string) *Receiver {
+	return &Receiver{
+		Database: db,
+		URL:      url,
+		Interval: time.Second,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Start polls until storing fails. Failing to poll is logged and retried.
+func (r *Receiver) Start() error {
+	if err := r.Database.Connect(); err != nil {
+		return err
+	}
+	for {
+		result, err := r.fetch()
+		if err != nil {
+			r.failed(err)
+		} else if _, err := r.Process(result); err != nil {
+			return err
+		}
+		time.Sleep(r.Interval)
+	}
+}
+
+// failed logs a failed poll, and reports it as an error of the station
+func (r *Receiver) failed(err error) {
+	log.WithError(err).Error("Failed to get result from KrakenSDR")
+	if r.Status == nil {
+		return
+	}
+	if r.Station != "" {
+		r.Status.Error(r.Station)
+	} else {
+		r.Status.Error(r.station)
+	}
+}
+
+// fetch gets and parses the current result
+func (r *Receiver) fetch() (*Result, error) {
+	response, err := r.client.Get(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Process stores a result, unless it was already stored. It returns the stored measurement.
+func (r *Receiver) Process(result *Result) (*types.Measurement, error) {
+	r.station = result.Station
+	if !result.Timestamp.After(r.last) {
+		return nil, nil
+	}
+	r.last = result.Timestamp
+
+	if r.Position != nil {
+		if fix := r.Position.Position(); fix != nil {
+			located := *result
+			located.Longitude, located.Latitude = fix.Longitude, fix.Latitude
+			if fix.HasCourse {
+				located.Heading = fix.Course
+			}
+			result = &located
+		}
+	}
+	m := result.Measurement(r.Relative)
+	if r.Station != "" {
+		m.Station = r.Station
+	}
+	if r.Detector != nil && r.Detector.Check(m) {
+		m.Suspect = true
+		log.WithField("bearing", m.Bearing).Warn("Bearing looks like an outlier")
+	}
+	log.WithField("station", m.Station).WithField("bearing", m.Bearing).Debug("Storing measurement")
+	if r.Status != nil {
+		r.Status.Measurement(m)
+	}
+	err := r.Database.Add(m)
+	if err != nil && r.Status != nil {
+		r.Status.Error(m.Station)
+	}
+	return m, err
+}
@@ -0,0 +1,90 @@
+package kraken
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/position/positiontest"
+	"github.com/hsmade/OSM-ARDF/pkg/status"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// poll fetches and processes the current result once
+func poll(t *testing.T, r *Receiver) *types.Measurement {
+	result, err := r.fetch()
+	if err != nil {
+		t.Fatalf("fetch() returned error: %e", err)
+	}
+	m, err := r.Process(result)
+	if err != nil {
+		t.Fatalf("Process() returned error: %e", err)
+	}
+	return m
+}
+
+func TestReceiver(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	db := &databasetest.Database{}
+	receiver := NewReceiver(db, server.URL+"/DOA_value.html")
+	m := poll(t, receiver)
+	if m == nil || m.Station != "fox-kraken" || m.Bearing != 72 || m.Quality != 8.53 || m.Frequency != 145.5 || len(m.Vector) != 360 {
+		t.Errorf("unexpected measurement: %v", m)
+	}
+	if m := poll(t, receiver); m != nil {
+		t.Errorf("stored the same result twice: %v", m)
+	}
+
+	// the vehicle result is newer, and relative to the heading of 90 degrees
+	receiver.URL = server.URL + "/doa.json"
+	receiver.Relative = true
+	receiver.Station = "kraken1"
+	m = poll(t, receiver)
+	if m == nil || m.Station != "kraken1" || m.Bearing != 120 || peak(m.Vector) != 120 {
+		t.Errorf("unexpected measurement: %v", m)
+	}
+	if len(db.Measurements()) != 2 {
+		t.Errorf("stored %d measurements, want 2", len(db.Measurements()))
+	}
+
+	// a provider overrides the position and the heading
+	receiver.Position = positiontest.Fixed{Longitude: 4.9, Latitude: 51.9, Course: 180, HasCourse: true}
+	receiver.last = time.Time{}
+	m = poll(t, receiver)
+	if m == nil || m.Longitude != 4.9 || m.Latitude != 51.9 || m.Bearing != 210 || peak(m.Vector) != 210 {
+		t.Errorf("unexpected measurement: %v", m)
+	}
+
+	receiver.URL = server.URL + "/missing"
+	if _, err := receiver.fetch(); err == nil {
+		t.Errorf("expected an error for a missing page")
+	}
+}
+
+func TestReceiver_failed(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	receiver := NewReceiver(&databasetest.Database{}, server.URL+"/DOA_value.html")
+	receiver.Status = status.NewRecorder(receiver.Database)
+	poll(t, receiver)
+
+	// failed polls are errors of the station of the last result
+	receiver.URL = server.URL + "/missing"
+	_, err := receiver.fetch()
+	receiver.failed(err)
+	reports := receiver.Status.Reports()
+	if len(reports) != 1 || reports[0].Station != "fox-kraken" || reports[0].Errors != 1 {
+		t.Errorf("unexpected reports %+v", reports)
+	}
+
+	receiver.Station = "kraken1"
+	receiver.failed(err)
+	reports = receiver.Status.Reports()
+	if len(reports) != 1 || reports[0].Station != "kraken1" || reports[0].Errors != 1 {
+		t.Errorf("unexpected reports %+v", reports)
+	}
+}
@@ -0,0 +1,162 @@
+package kraken
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/circular"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result is a direction of arrival result of a KrakenSDR. Angles are clockwise, like a compass.
+type Result struct {
+	Timestamp  time.Time
+	Station    string
+	Longitude  float64
+	Latitude   float64
+	Heading    float64 // heading of the vehicle the array is mounted on
+	DoA        float64 // direction of arrival with the highest confidence
+	Confidence float64 // 0 - 99
+	Power      float64 // RSSI in dB
+	Frequency  float64 // in MHz
+	Vector     []float64
+}
+
+// jsonResult is the JSON that the KrakenSDR sends to a remote server
+type jsonResult struct {
+	StationID    string    `json:"station_id"`
+	Frequency    float64   `json:"freq"` // in kHz
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	GPSBearing   float64   `json:"gpsBearing"`
+	RadioBearing float64   `json:"radioBearing"`
+	Confidence   float64   `json:"conf"`
+	Power        float64   `json:"power"`
+	Timestamp    int64     `json:"tStamp"` // in milliseconds
+	Vector       []float64 `json:"doaArray"`
+}
+
+// csvFields is the amount of fields before the DoA vector in the Kraken App format
+const csvFields = 17
+
+// Parse parses a result in either the JSON or the Kraken App (DOA_value.html) format
+func Parse(data []byte) (*Result, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		return ParseJSON(data)
+	}
+	return ParseCSV(string(data))
+}
+
+// ParseJSON parses the JSON format
+func ParseJSON(data []byte) (*Result, error) {
+	var result jsonResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	if result.Timestamp == 0 {
+		return nil, errors.New("missing timestamp")
+	}
+	return &Result{
+		Timestamp:  time.Unix(0, result.Timestamp*int64(time.Millisecond)).UTC(),
+		Station:    result.StationID,
+		Longitude:  result.Longitude,
+		Latitude:   result.Latitude,
+		Heading:    result.GPSBearing,
+		DoA:        result.RadioBearing,
+		Confidence: result.Confidence,
+		Power:      result.Power,
+		Frequency:  result.Frequency / 1e3,
+		Vector:     result.Vector,
+	}, nil
+}
+
+// ParseCSV parses a line in the Kraken App format: time in ms, DoA, confidence, RSSI, frequency in Hz,
+// array arrangement, latency, station id, latitude, longitude, GPS heading, compass heading,
+// main heading sensor, four reserved fields and the DoA vector.
+func ParseCSV(line string) (*Result, error) {
+	fields := strings.Split(line, ",")
+	if len(fields) < csvFields {
+		return nil, fmt.Errorf("expected at least %d fields, got %d", csvFields, len(fields))
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	numbers := map[int]float64{}
+	for _, i := range []int{0, 1, 2, 3, 4, 8, 9, 10, 11} {
+		number, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %v", i+1, err)
+		}
+		numbers[i] = number
+	}
+	heading := numbers[10]
+	if strings.EqualFold(fields[12], "compass") {
+		heading = numbers[11]
+	}
+
+	var vector []float64
+	for i, field := range fields[csvFields:] {
+		if field == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %v", csvFields+i+1, err)
+		}
+		vector = append(vector, value)
+	}
+
+	return &Result{
+		Timestamp:  time.Unix(0, int64(numbers[0])*int64(time.Millisecond)).UTC(),
+		Station:    fields[7],
+		Longitude:  numbers[9],
+		Latitude:   numbers[8],
+		Heading:    heading,
+		DoA:        numbers[1],
+		Confidence: numbers[2],
+		Power:      numbers[3],
+		Frequency:  numbers[4] / 1e6,
+		Vector:     vector,
+	}, nil
+}
+
+// Measurement converts the result. When relative is set the DoA is relative to the heading,
+// and the bearing and vector are turned by the heading.
+func (r *Result) Measurement(relative bool) *types.Measurement {
+	bearing := r.DoA
+	vector := r.Vector
+	if relative {
+		bearing += r.Heading
+		vector = rotate(r.Vector, r.Heading)
+	}
+	return &types.Measurement{
+		Timestamp: r.Timestamp,
+		Station:   r.Station,
+		Longitude: r.Longitude,
+		Latitude:  r.Latitude,
+		Bearing:   int(math.Round(circular.Normalise(bearing))) % 360,
+		Quality:   r.Confidence,
+		Frequency: r.Frequency,
+		Vector:    vector,
+	}
+}
+
+// rotate turns a vector that covers the full circle clockwise by the angle, rounded to the resolution of the vector
+func rotate(vector []float64, angle float64) []float64 {
+	if len(vector) == 0 {
+		return vector
+	}
+	shift := int(math.Round(circular.Normalise(angle)/360*float64(len(vector)))) % len(vector)
+	rotated := make([]float64, len(vector))
+	for i, value := range vector {
+		rotated[(i+shift)%len(vector)] = value
+	}
+	return rotated
+}
@@ -0,0 +1,91 @@
+package kraken
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		file       string
+		want       Result
+		wantVector int
+		wantPeak   int
+	}{
+		{"DOA_value.html", Result{
+			Timestamp:  time.Date(2019, 10, 2, 12, 0, 0, 250000000, time.UTC),
+			Station:    "fox-kraken",
+			Longitude:  5.1214,
+			Latitude:   52.0901,
+			DoA:        72,
+			Confidence: 8.53,
+			Power:      -48.2,
+			Frequency:  145.5,
+		}, 360, 72},
+		{"doa.json", Result{
+			Timestamp:  time.Date(2019, 10, 2, 12, 0, 1, 250000000, time.UTC),
+			Station:    "car",
+			Longitude:  5.2,
+			Latitude:   52.1,
+			Heading:    90,
+			DoA:        30,
+			Confidence: 6.2,
+			Power:      -51.5,
+			Frequency:  145.5,
+		}, 360, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := ioutil.ReadFile("testdata/" + tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse() returned error: %e", err)
+			}
+			if len(got.Vector) != tt.wantVector || peak(got.Vector) != tt.wantPeak {
+				t.Errorf("vector has %d values with the peak at %d", len(got.Vector), peak(got.Vector))
+			}
+			got.Vector = nil
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Parse() got = %v, want %v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, data := range []string{"", "1, 2, 3", `{"station_id": "car"}`, `{"tStamp": "now"}`,
+		"now, 72, 8.53, -48.2, 145500000, UCA, 312, fox, 52, 5, 0, 0, GPS, R, R, R, R",
+		"1, 72, 8.53, -48.2, 145500000, UCA, 312, fox, 52, 5, 0, 0, GPS, R, R, R, R, 1, x"} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+}
+
+func TestResult_Measurement(t *testing.T) {
+	result := Result{DoA: 300, Heading: 90, Vector: []float64{1, 2, 3, 4}}
+	m := result.Measurement(false)
+	if m.Bearing != 300 || !reflect.DeepEqual(m.Vector, []float64{1, 2, 3, 4}) {
+		t.Errorf("absolute: got bearing %d and vector %v", m.Bearing, m.Vector)
+	}
+	m = result.Measurement(true)
+	if m.Bearing != 30 || !reflect.DeepEqual(m.Vector, []float64{4, 1, 2, 3}) {
+		t.Errorf("relative: got bearing %d and vector %v", m.Bearing, m.Vector)
+	}
+}
+
+// peak returns the index of the highest value
+func peak(vector []float64) int {
+	best := 0
+	for i, value := range vector {
+		if value > vector[best] {
+			best = i
+		}
+	}
+	return best
+}
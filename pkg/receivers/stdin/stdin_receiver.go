@@ -5,12 +5,20 @@ import (
 	"encoding/json"
 	"github.com/apex/log"
 	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/outlier"
+	"github.com/hsmade/OSM-ARDF/pkg/position"
+	"github.com/hsmade/OSM-ARDF/pkg/smoothing"
+	"github.com/hsmade/OSM-ARDF/pkg/status"
 	"github.com/hsmade/OSM-ARDF/pkg/types"
 	"io"
 )
 
 type Receiver struct {
 	Database database.Database
+	Detector *outlier.Detector   // optional, flags suspect bearings before storing them
+	Smoother *smoothing.Smoother // optional, smoothens the bearings of a station before storing them
+	Position position.Provider   // optional, locates measurements without coordinates
+	Status   *status.Recorder    // optional, reports the liveness of the stations
 }
 
 func (r *Receiver) Start(reader io.Reader) error {
@@ -37,7 +45,34 @@ func (r *Receiver) process(data string) {
 		log.WithError(err).Error("Failed to parse into measurement")
 		return
 	}
-	defer log.WithField("measurement", m).Trace("storing measurement").Stop(&err)
-	log.WithField("measurement", m).Debug("Storing measurement")
-	err = r.Database.Add(&m)
+	if m.Longitude == 0 && m.Latitude == 0 && r.Position != nil {
+		if fix := r.Position.Position(); fix != nil {
+			m.Longitude, m.Latitude = fix.Longitude, fix.Latitude
+		}
+	}
+	if r.Status != nil {
+		r.Status.Measurement(&m)
+	}
+	if r.Smoother != nil {
+		smoothed := r.Smoother.Add(&m)
+		if smoothed == nil {
+			return
+		}
+		m = *smoothed
+	}
+	r.store(&m)
+}
+
+func (r *Receiver) store(m *types.Measurement) {
+	var err error
+	if r.Detector != nil && r.Detector.Check(m) {
+		m.Suspect = true
+		log.WithField("measurement", *m).Warn("Bearing looks like an outlier")
+	}
+	defer log.WithField("measurement", *m).Trace("storing measurement").Stop(&err)
+	log.WithField("measurement", *m).Debug("Storing measurement")
+	err = r.Database.Add(m)
+	if err != nil && r.Status != nil {
+		r.Status.Error(m.Station)
+	}
 }
@@ -3,101 +3,48 @@ package stdin
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/outlier"
+	"github.com/hsmade/OSM-ARDF/pkg/position"
+	"github.com/hsmade/OSM-ARDF/pkg/smoothing"
 	"github.com/hsmade/OSM-ARDF/pkg/types"
 	"reflect"
 	"testing"
 	"time"
 )
 
-type databaseMock struct {
-	value        *types.Measurement
-	measurements int
-	Test         *testing.T
-}
-
-func (d *databaseMock) Add(m *types.Measurement) error {
-	d.Test.Logf("Add(%v)", *m)
-	d.value = m
-	d.measurements++
-	return nil
-}
-
-func (d *databaseMock) Connect() error {
-	return nil
-}
-
-func (d *databaseMock) GetPositions(since time.Duration) ([]*types.Position, error) {
-	return nil, nil
-}
-
-func (d *databaseMock) GetLines(since time.Duration) ([]*types.Line, error) {
-	return nil, nil
-}
-
-func (d *databaseMock) GetCrossings(since time.Duration) ([]*types.Crossing, error) {
-	return nil, nil
-}
-
-type databaseMockNoConnect struct {
-	value        *types.Measurement
-	measurements int
-	Test         *testing.T
-}
-
-func (d *databaseMockNoConnect) Add(m *types.Measurement) error {
-	d.Test.Logf("Add(%v)", *m)
-	d.value = m
-	d.measurements++
-	return nil
-}
-
-func (d *databaseMockNoConnect) Connect() error {
-	return errors.New("test")
-}
-
-func (d *databaseMockNoConnect) GetPositions(since time.Duration) ([]*types.Position, error) {
-	return nil, nil
-}
-
-func (d *databaseMockNoConnect) GetLines(since time.Duration) ([]*types.Line, error) {
-	return nil, nil
-}
-
-func (d *databaseMockNoConnect) GetCrossings(since time.Duration) ([]*types.Crossing, error) {
-	return nil, nil
-}
-
 func TestReceiver_Start_Happy_flow(t *testing.T) {
-	db := &databaseMock{Test: t}
+	db := &databasetest.Database{}
 	r := &Receiver{Database: db}
 	err := r.Start(bytes.NewReader([]byte("{}")))
 	if err != nil {
 		t.Errorf("Got unexpected error %v", err)
 	}
 
-	if !reflect.DeepEqual(types.Measurement{}, *db.value) {
-		t.Errorf("Got unexpected measurement, should be empty: %v", *db.value)
+	if !reflect.DeepEqual(types.Measurement{}, *db.Last()) {
+		t.Errorf("Got unexpected measurement, should be empty: %v", *db.Last())
 	}
 
-	if db.measurements != 1 {
-		t.Errorf("Got unexpected amount of measurements (need 1): %v", db.measurements)
+	if len(db.Measurements()) != 1 {
+		t.Errorf("Got unexpected amount of measurements (need 1): %v", len(db.Measurements()))
 	}
 }
 
 func TestReceiver_Start_No_connect(t *testing.T) {
-	db := &databaseMockNoConnect{Test: t}
+	db := &databasetest.Database{ConnectErr: errors.New("test")}
 	r := &Receiver{Database: db}
 	err := r.Start(bytes.NewReader([]byte("{}")))
 	if !reflect.DeepEqual(err, errors.New("test")) {
 		t.Errorf("Got unexpected error %v", err)
 	}
 
-	if db.value != nil {
-		t.Errorf("Got unexpected measurement, should be empty: %v", *db.value)
+	if db.Last() != nil {
+		t.Errorf("Got unexpected measurement, should be empty: %v", *db.Last())
 	}
 
-	if db.measurements != 0 {
-		t.Errorf("Got unexpected amount of measurements (need 0): %v", db.measurements)
+	if len(db.Measurements()) != 0 {
+		t.Errorf("Got unexpected amount of measurements (need 0): %v", len(db.Measurements()))
 	}
 }
 
@@ -134,12 +81,84 @@ func TestReceiver_process(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			db := &databaseMock{Test: t, value: &types.Measurement{}}
+			db := &databasetest.Database{}
 			r := &Receiver{Database: db}
 			r.process(tt.data)
-			if !reflect.DeepEqual(*db.value, tt.result) {
-				t.Errorf("Process() = %v, want %v", *db.value, tt.result)
+			got := types.Measurement{}
+			if last := db.Last(); last != nil {
+				got = *last
+			}
+			if !reflect.DeepEqual(got, tt.result) {
+				t.Errorf("Process() = %v, want %v", got, tt.result)
 			}
 		})
 	}
 }
+
+func TestReceiver_process_Detector(t *testing.T) {
+	db := &databasetest.Database{}
+	r := &Receiver{Database: db, Detector: outlier.New()}
+	for _, bearing := range []int{10, 11, 10, 12, 10} {
+		r.process(fmt.Sprintf("{\"timestamp\":\"2018-09-22T12:42:31Z\", \"station\":\"abc\", \"bearing\": %d}", bearing))
+		if db.Last().Suspect {
+			t.Errorf("Got unexpected suspect measurement: %v", *db.Last())
+		}
+	}
+
+	r.process("{\"timestamp\":\"2018-09-22T12:42:32Z\", \"station\":\"abc\", \"bearing\": 200}")
+	if !db.Last().Suspect {
+		t.Errorf("Expected measurement to be flagged as suspect: %v", *db.Last())
+	}
+	if len(db.Measurements()) != 6 {
+		t.Errorf("Got unexpected amount of measurements (need 6): %v", len(db.Measurements()))
+	}
+}
+
+func TestReceiver_Start_Smoother(t *testing.T) {
+	db := &databasetest.Database{}
+	r := &Receiver{Database: db, Smoother: smoothing.New(time.Minute)}
+	input := "{\"timestamp\":\"2018-09-22T12:42:31Z\", \"station\":\"abc\", \"bearing\": 10}\n" +
+		"{\"timestamp\":\"2018-09-22T12:42:32Z\", \"station\":\"abc\", \"bearing\": 20}\n" +
+		"{\"timestamp\":\"2018-09-22T12:43:31Z\", \"station\":\"abc\", \"bearing\": 30}\n"
+	err := r.Start(bytes.NewReader([]byte(input)))
+	if err != nil {
+		t.Errorf("Got unexpected error %v", err)
+	}
+
+	// a smoothed bearing per minute is stored
+	if len(db.Measurements()) != 2 {
+		t.Errorf("Got unexpected amount of measurements (need 2): %v", len(db.Measurements()))
+	}
+	if db.Last().Bearing != 25 || db.Last().Spread == 0 {
+		t.Errorf("Got unexpected measurement, should be smoothed: %v", *db.Last())
+	}
+}
+
+type positionMock struct {
+	fix *position.Fix
+}
+
+func (p *positionMock) Position() *position.Fix {
+	return p.fix
+}
+
+func TestReceiver_process_Position(t *testing.T) {
+	db := &databasetest.Database{}
+	provider := &positionMock{}
+	r := &Receiver{Database: db, Position: provider}
+
+	r.process("{\"station\":\"abc\", \"bearing\": 10}")
+	if db.Last().Longitude != 0 || db.Last().Latitude != 0 {
+		t.Errorf("Got unexpected position without a fix: %v", *db.Last())
+	}
+
+	provider.fix = &position.Fix{Longitude: 5, Latitude: 52}
+	r.process("{\"station\":\"abc\", \"bearing\": 10}")
+	if db.Last().Longitude != 5 || db.Last().Latitude != 52 {
+		t.Errorf("Got unexpected position with a fix: %v", *db.Last())
+	}
+	r.process("{\"station\":\"abc\", \"longitude\": 4, \"latitude\": 51, \"bearing\": 10}")
+	if db.Last().Longitude != 4 || db.Last().Latitude != 51 {
+		t.Errorf("Got unexpected position, should be the measured one: %v", *db.Last())
+	}
+}
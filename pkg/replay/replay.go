@@ -0,0 +1,56 @@
+// Package replay re-emits recorded measurements with their original relative timing
+package replay
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"sort"
+	"time"
+)
+
+type Replayer struct {
+	Speed  float64 // 1 is real time, 10 is ten times faster, 0 is as fast as possible
+	Rebase bool    // move the timestamps as if the measurements happen now
+	sleep  func(time.Duration)
+	now    func() time.Time
+}
+
+// New returns a replayer that replays in real time and keeps the original timestamps
+func New() *Replayer {
+	return &Replayer{
+		Speed: 1,
+		sleep: time.Sleep,
+		now:   time.Now,
+	}
+}
+
+// Replay sorts the measurements by time and passes them to emit, waiting between them as long as the
+// time between the recorded measurements divided by the speed. It stops at the first error of emit.
+func (r *Replayer) Replay(measurements []*types.Measurement, emit func(m *types.Measurement) error) error {
+	if len(measurements) == 0 {
+		return nil
+	}
+	sorted := make([]*types.Measurement, len(measurements))
+	copy(sorted, measurements)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	first := sorted[0].Timestamp
+	start := r.now()
+	for _, m := range sorted {
+		offset := m.Timestamp.Sub(first)
+		if r.Speed > 0 {
+			offset = time.Duration(float64(offset) / r.Speed)
+			if wait := start.Add(offset).Sub(r.now()); wait > 0 {
+				r.sleep(wait)
+			}
+		}
+
+		replayed := *m
+		if r.Rebase {
+			replayed.Timestamp = start.Add(offset)
+		}
+		if err := emit(&replayed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package replay
+
+import (
+	"errors"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when sleeping
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) sleep(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) time() time.Time {
+	return c.now
+}
+
+func TestReplayer_Replay(t *testing.T) {
+	recorded := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	replayed := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	measurements := []*types.Measurement{
+		{Timestamp: recorded.Add(10 * time.Second), Station: "b"},
+		{Timestamp: recorded, Station: "a"},
+		{Timestamp: recorded.Add(30 * time.Second), Station: "c"},
+	}
+
+	tests := []struct {
+		name       string
+		speed      float64
+		rebase     bool
+		wantSleeps []time.Duration
+		wantTimes  []time.Time
+	}{
+		{
+			name:       "real time",
+			speed:      1,
+			wantSleeps: []time.Duration{10 * time.Second, 20 * time.Second},
+			wantTimes:  []time.Time{recorded, recorded.Add(10 * time.Second), recorded.Add(30 * time.Second)},
+		},
+		{
+			name:       "ten times faster and rebased",
+			speed:      10,
+			rebase:     true,
+			wantSleeps: []time.Duration{time.Second, 2 * time.Second},
+			wantTimes:  []time.Time{replayed, replayed.Add(time.Second), replayed.Add(3 * time.Second)},
+		},
+		{
+			name:      "as fast as possible",
+			speed:     0,
+			rebase:    true,
+			wantTimes: []time.Time{replayed, replayed.Add(10 * time.Second), replayed.Add(30 * time.Second)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: replayed}
+			r := &Replayer{Speed: tt.speed, Rebase: tt.rebase, sleep: clock.sleep, now: clock.time}
+
+			var times []time.Time
+			var stations []string
+			err := r.Replay(measurements, func(m *types.Measurement) error {
+				times = append(times, m.Timestamp)
+				stations = append(stations, m.Station)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Replay() returned error: %e", err)
+			}
+			if !reflect.DeepEqual(stations, []string{"a", "b", "c"}) {
+				t.Errorf("replayed out of order: %v", stations)
+			}
+			if !reflect.DeepEqual(clock.sleeps, tt.wantSleeps) {
+				t.Errorf("slept %v, want %v", clock.sleeps, tt.wantSleeps)
+			}
+			if !reflect.DeepEqual(times, tt.wantTimes) {
+				t.Errorf("replayed with times %v, want %v", times, tt.wantTimes)
+			}
+		})
+	}
+
+	if !measurements[0].Timestamp.Equal(recorded.Add(10 * time.Second)) {
+		t.Errorf("the recording was changed")
+	}
+}
+
+func TestReplayer_Replay_Error(t *testing.T) {
+	r := New()
+	r.Speed = 0
+	calls := 0
+	err := r.Replay([]*types.Measurement{{}, {}}, func(m *types.Measurement) error {
+		calls++
+		return errors.New("test")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected to stop at the first error, got %v after %d calls", err, calls)
+	}
+}
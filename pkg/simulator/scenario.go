@@ -0,0 +1,199 @@
+// Package simulator generates the measurements of a simulated hunt, with configurable bearing errors
+package simulator
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kellydunn/golang-geo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scenario describes a simulated hunt. It is read from a JSON file.
+type Scenario struct {
+	Seed        int64      `json:"seed"`
+	Start       time.Time  `json:"start"`
+	Duration    Duration   `json:"duration"`
+	Interval    Duration   `json:"interval"` // time between the bearings of a station
+	Transmitter Path       `json:"transmitter"`
+	Stations    []Station  `json:"stations"`
+	Errors      ErrorModel `json:"errors"` // used for stations without their own error model
+}
+
+// Path is a route that is followed at constant speed during the whole scenario.
+// A path with a single point is a fixed position.
+type Path struct {
+	File   string       `json:"file"`   // file with a longitude,latitude[,altitude] per line
+	Points [][2]float64 `json:"points"` // longitude, latitude pairs, used when there is no file
+}
+
+type Station struct {
+	Name   string      `json:"name"`
+	Route  Path        `json:"route"`
+	Errors *ErrorModel `json:"errors"`
+}
+
+type ErrorModel struct {
+	Distribution string  `json:"distribution"` // normal (default), uniform or laplace
+	Deviation    float64 `json:"deviation"`    // standard deviation of the bearing noise, in degrees
+	Bias         float64 `json:"bias"`         // added to every bearing, in degrees
+	Dropout      float64 `json:"dropout"`      // chance that a bearing is not sent, 0 - 1
+	Outliers     float64 `json:"outliers"`     // chance that a bearing comes from a reflection in a random direction, 0 - 1
+}
+
+// Duration is a time.Duration that is written like "10m" in JSON
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+// Load reads a scenario file. Route files are relative to the scenario file.
+func Load(filename string) (*Scenario, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scenario := Scenario{}
+	if err := json.NewDecoder(file).Decode(&scenario); err != nil {
+		return nil, err
+	}
+
+	directory := filepath.Dir(filename)
+	if err := scenario.Transmitter.load(directory); err != nil {
+		return nil, err
+	}
+	for i := range scenario.Stations {
+		if err := scenario.Stations[i].Route.load(directory); err != nil {
+			return nil, err
+		}
+	}
+	return &scenario, scenario.validate()
+}
+
+func (s *Scenario) validate() error {
+	if s.Duration.Duration <= 0 || s.Interval.Duration <= 0 {
+		return errors.New("duration and interval must be set")
+	}
+	if len(s.Transmitter.Points) == 0 {
+		return errors.New("transmitter has no path")
+	}
+	models := []*ErrorModel{&s.Errors}
+	for _, station := range s.Stations {
+		if station.Name == "" || len(station.Route.Points) == 0 {
+			return errors.New("every station needs a name and a route")
+		}
+		if station.Errors != nil {
+			models = append(models, station.Errors)
+		}
+	}
+	for _, model := range models {
+		switch model.Distribution {
+		case "", "normal", "uniform", "laplace":
+		default:
+			return errors.New("unknown distribution: " + model.Distribution)
+		}
+	}
+	return nil
+}
+
+// load reads the points from the file of the path, if it has one
+func (p *Path) load(directory string) error {
+	if p.File == "" {
+		return nil
+	}
+	filename := p.File
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(directory, filename)
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	p.Points, err = readCoordinates(file)
+	if err != nil {
+		return fmt.Errorf("%s: %s", p.File, err)
+	}
+	return nil
+}
+
+func readCoordinates(reader io.Reader) ([][2]float64, error) {
+	var points [][2]float64
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		fields := strings.Split(text, ",")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected longitude,latitude", line)
+		}
+		longitude, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		latitude, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		points = append(points, [2]float64{longitude, latitude})
+	}
+	return points, scanner.Err()
+}
+
+// at returns the position on the path after the given fraction (0 - 1) of the scenario.
+// The fraction is taken of the length of the path, so every segment is travelled at the same speed.
+func (p *Path) at(fraction float64) (longitude, latitude float64) {
+	if len(p.Points) == 1 || fraction <= 0 {
+		return p.Points[0][0], p.Points[0][1]
+	}
+	if fraction >= 1 {
+		last := p.Points[len(p.Points)-1]
+		return last[0], last[1]
+	}
+
+	lengths := make([]float64, len(p.Points)-1)
+	var total float64
+	for i := range lengths {
+		from, to := p.Points[i], p.Points[i+1]
+		lengths[i] = geo.NewPoint(from[1], from[0]).GreatCircleDistance(geo.NewPoint(to[1], to[0]))
+		total += lengths[i]
+	}
+
+	distance := fraction * total
+	for i, length := range lengths {
+		if distance > length && i < len(lengths)-1 {
+			distance -= length
+			continue
+		}
+		rest := 0.0
+		if length > 0 {
+			rest = distance / length
+		}
+		from, to := p.Points[i], p.Points[i+1]
+		return from[0] + (to[0]-from[0])*rest, from[1] + (to[1]-from[1])*rest
+	}
+	return p.Points[0][0], p.Points[0][1]
+}
@@ -0,0 +1,63 @@
+package simulator
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/circular"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/kellydunn/golang-geo"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Measurements returns the measurements of all stations during the scenario, sorted by time.
+// The same scenario and seed always give the same measurements.
+func (s *Scenario) Measurements() []*types.Measurement {
+	random := rand.New(rand.NewSource(s.Seed))
+	var measurements []*types.Measurement
+	for offset := time.Duration(0); offset <= s.Duration.Duration; offset += s.Interval.Duration {
+		fraction := offset.Seconds() / s.Duration.Seconds()
+		transmitterLongitude, transmitterLatitude := s.Transmitter.at(fraction)
+		transmitter := geo.NewPoint(transmitterLatitude, transmitterLongitude)
+
+		for _, station := range s.Stations {
+			model := s.Errors
+			if station.Errors != nil {
+				model = *station.Errors
+			}
+			if random.Float64() < model.Dropout {
+				continue
+			}
+
+			longitude, latitude := station.Route.at(fraction)
+			bearing := geo.NewPoint(latitude, longitude).BearingTo(transmitter)
+			if random.Float64() < model.Outliers {
+				bearing = random.Float64() * 360
+			} else {
+				bearing += model.Bias + model.noise(random)
+			}
+
+			measurements = append(measurements, &types.Measurement{
+				Timestamp: s.Start.Add(offset),
+				Station:   station.Name,
+				Longitude: longitude,
+				Latitude:  latitude,
+				Bearing:   int(math.Round(circular.Normalise(bearing))) % 360,
+			})
+		}
+	}
+	return measurements
+}
+
+// noise returns a random bearing error with the distribution and standard deviation of the model
+func (e *ErrorModel) noise(random *rand.Rand) float64 {
+	switch e.Distribution {
+	case "uniform":
+		// a uniform distribution of width w has a standard deviation of w / sqrt(12)
+		return (random.Float64() - 0.5) * e.Deviation * math.Sqrt(12)
+	case "laplace":
+		// a laplace distribution with scale b has a standard deviation of b * sqrt(2)
+		return random.ExpFloat64() * e.Deviation / math.Sqrt2 * float64(1-2*random.Intn(2))
+	default:
+		return random.NormFloat64() * e.Deviation
+	}
+}
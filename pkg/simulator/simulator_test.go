@@ -0,0 +1,149 @@
+package simulator
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/circular"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testScenario() *Scenario {
+	return &Scenario{
+		Seed:        42,
+		Start:       time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC),
+		Duration:    Duration{10 * time.Second},
+		Interval:    Duration{time.Second},
+		Transmitter: Path{Points: [][2]float64{{5, 52}}},
+		Stations: []Station{
+			{Name: "south", Route: Path{Points: [][2]float64{{5, 51.9}}}},
+			{Name: "west", Route: Path{Points: [][2]float64{{4.9, 52}, {4.8, 52}}}},
+		},
+	}
+}
+
+func TestScenario_Measurements(t *testing.T) {
+	measurements := testScenario().Measurements()
+	if len(measurements) != 22 {
+		t.Fatalf("expected 22 measurements, got %d", len(measurements))
+	}
+	for _, m := range measurements {
+		want := map[string]int{"south": 0, "west": 90}[m.Station]
+		if m.Bearing != want {
+			t.Errorf("expected a bearing of %d without errors, got %+v", want, *m)
+		}
+	}
+	last := measurements[len(measurements)-1]
+	if last.Station != "west" || last.Longitude != 4.8 || !last.Timestamp.Equal(testScenario().Start.Add(10*time.Second)) {
+		t.Errorf("expected the last measurement at the end of the route, got %+v", *last)
+	}
+}
+
+func TestScenario_Measurements_Errors(t *testing.T) {
+	scenario := testScenario()
+	scenario.Duration = Duration{time.Hour}
+	scenario.Errors = ErrorModel{Deviation: 3, Bias: 2, Dropout: 0.5}
+	measurements := scenario.Measurements()
+
+	if !reflect.DeepEqual(measurements, scenario.Measurements()) {
+		t.Errorf("the same seed gave different measurements")
+	}
+	if math.Abs(float64(len(measurements))-3601) > 150 {
+		t.Errorf("expected about half of the 7202 bearings to drop out, got %d", len(measurements))
+	}
+
+	var errors []float64
+	for _, m := range measurements {
+		if m.Station == "south" {
+			errors = append(errors, float64(m.Bearing))
+		}
+	}
+	mean, spread := circular.Mean(errors)
+	if math.Abs(circular.Difference(mean, 2)) > 0.3 || math.Abs(spread-3) > 0.3 {
+		t.Errorf("expected a bias of 2 and a deviation of 3, got %f and %f", mean, spread)
+	}
+}
+
+func TestErrorModel_noise(t *testing.T) {
+	for _, distribution := range []string{"normal", "uniform", "laplace"} {
+		t.Run(distribution, func(t *testing.T) {
+			model := ErrorModel{Distribution: distribution, Deviation: 4}
+			random := rand.New(rand.NewSource(1))
+			var sum, squares float64
+			for i := 0; i < 100000; i++ {
+				noise := model.noise(random)
+				sum += noise
+				squares += noise * noise
+			}
+			mean := sum / 100000
+			deviation := math.Sqrt(squares/100000 - mean*mean)
+			if math.Abs(mean) > 0.1 || math.Abs(deviation-4) > 0.1 {
+				t.Errorf("expected mean 0 and deviation 4, got %f and %f", mean, deviation)
+			}
+		})
+	}
+}
+
+func TestPath_at(t *testing.T) {
+	// the second segment is three times as long as the first
+	path := Path{Points: [][2]float64{{5, 52}, {5, 52.01}, {5, 52.04}}}
+	for _, tt := range []struct{ fraction, latitude float64 }{
+		{-1, 52}, {0.25, 52.01}, {0.5, 52.02}, {1, 52.04},
+	} {
+		longitude, latitude := path.at(tt.fraction)
+		if longitude != 5 || math.Abs(latitude-tt.latitude) > 1e-6 {
+			t.Errorf("at(%f) = %f, %f, want 5, %f", tt.fraction, longitude, latitude, tt.latitude)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	directory, err := ioutil.TempDir("", "scenario")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	write := func(name, content string) string {
+		filename := filepath.Join(directory, name)
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	write("route.coordinates", "5.1,52.1,0\n5.2,52.2,0\n")
+	scenario, err := Load(write("scenario.json", `{
+		"duration": "1m", "interval": "5s",
+		"transmitter": {"points": [[5, 52]]},
+		"stations": [{"name": "car1", "route": {"file": "route.coordinates"}, "errors": {"distribution": "uniform", "deviation": 2}}]
+	}`))
+	if err != nil {
+		t.Fatalf("Load() returned error: %e", err)
+	}
+	if scenario.Duration.Duration != time.Minute || !reflect.DeepEqual(scenario.Stations[0].Route.Points, [][2]float64{{5.1, 52.1}, {5.2, 52.2}}) {
+		t.Errorf("unexpected scenario: %+v", scenario)
+	}
+
+	_, err = Load(write("invalid.json", `{"duration": "1m", "interval": "5s", "transmitter": {"points": [[5, 52]]}, "errors": {"distribution": "cauchy"}}`))
+	if err == nil || !strings.Contains(err.Error(), "cauchy") {
+		t.Errorf("expected an error for an unknown distribution, got %v", err)
+	}
+	if _, err := Load(write("missing.json", `{"duration": "1m", "interval": "5s", "transmitter": {"file": "missing"}}`)); err == nil {
+		t.Errorf("expected an error for a missing route file")
+	}
+}
+
+func TestLoad_Example(t *testing.T) {
+	scenario, err := Load("../../scripts/scenario.json")
+	if err != nil {
+		t.Fatalf("failed to load the example scenario: %e", err)
+	}
+	if len(scenario.Measurements()) == 0 {
+		t.Errorf("the example scenario has no measurements")
+	}
+}
@@ -0,0 +1,82 @@
+// Package smoothing replaces the bearings a station sends by the combination of its bearings over a short trailing window,
+// at most once per interval, to tame noisy Doppler units that send several bearings a second
+package smoothing
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/circular"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"sync"
+	"time"
+)
+
+type Smoother struct {
+	Window   time.Duration // bearings of a station within this window before a bearing are combined with it
+	Interval time.Duration // minimal time between the smoothed bearings of a station, 0 for one per measurement
+	Median   bool          // use the circular median instead of the circular mean
+	mutex    sync.Mutex
+	history  map[string][]*types.Measurement
+	emitted  map[string]time.Time // timestamp of the last smoothed bearing of each station
+}
+
+// New returns a smoother with the given window that uses the circular mean, and gives a bearing per window
+func New(window time.Duration) *Smoother {
+	return &Smoother{
+		Window:   window,
+		Interval: window,
+		history:  map[string][]*types.Measurement{},
+		emitted:  map[string]time.Time{},
+	}
+}
+
+// Add remembers the measurement and returns it with its bearing smoothed over the bearings of its station
+// in the window that ends at its timestamp. It returns nil when the station got a smoothed bearing less than
+// the interval before, as that measurement is part of the next one. Nothing waits for a next measurement.
+func (s *Smoother) Add(m *types.Measurement) *types.Measurement {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	history := append(s.history[m.Station], m)
+	var newest time.Time
+	for _, remembered := range history {
+		if remembered.Timestamp.After(newest) {
+			newest = remembered.Timestamp
+		}
+	}
+
+	var kept, window []*types.Measurement
+	for _, remembered := range history {
+		if remembered.Timestamp.After(m.Timestamp.Add(-s.Window)) && !remembered.Timestamp.After(m.Timestamp) {
+			window = append(window, remembered)
+		}
+		if remembered.Timestamp.After(newest.Add(-s.Window)) {
+			kept = append(kept, remembered)
+		}
+	}
+	s.history[m.Station] = kept
+
+	if last, found := s.emitted[m.Station]; found && s.Interval > 0 && m.Timestamp.Sub(last) < s.Interval {
+		return nil
+	}
+	s.emitted[m.Station] = m.Timestamp
+	return s.combine(m, window)
+}
+
+// combine returns the measurement with the bearing and spread of the window
+func (s *Smoother) combine(m *types.Measurement, window []*types.Measurement) *types.Measurement {
+	var bearings []float64
+	for _, w := range window {
+		bearings = append(bearings, float64(w.Bearing))
+	}
+
+	bearing, spread := circular.Mean(bearings)
+	if s.Median {
+		bearing = circular.Median(bearings)
+		spread = circular.MedianDeviation(bearings, bearing)
+	}
+
+	result := *m
+	result.Bearing = int(math.Round(bearing)) % 360
+	result.Spread = spread
+	return &result
+}
@@ -0,0 +1,85 @@
+package smoothing
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSmoother_Add(t *testing.T) {
+	start := time.Now()
+	s := New(time.Second)
+	s.Interval = 0
+
+	want := []struct {
+		bearing int
+		spread  float64
+	}{{358, 0}, {1, 3}, {1, 2.45}, {2, 1.41}}
+	for i, bearing := range []int{358, 4, 1, 1} {
+		m := &types.Measurement{Timestamp: start.Add(time.Duration(i) * 400 * time.Millisecond), Station: "car1", Longitude: float64(i), Bearing: bearing}
+		got := s.Add(m)
+		if got.Bearing != want[i].bearing || math.Abs(got.Spread-want[i].spread) > 0.01 || got.Longitude != m.Longitude || !got.Timestamp.Equal(m.Timestamp) {
+			t.Errorf("Add(%d) = %+v, want bearing %d with a spread of %.2f at its own position", bearing, got, want[i].bearing, want[i].spread)
+		}
+	}
+
+	if got := s.Add(&types.Measurement{Timestamp: start.Add(time.Second), Station: "car2", Bearing: 90}); got.Bearing != 90 || got.Spread != 0 {
+		t.Errorf("Add() = %+v, combined with the bearings of another station", got)
+	}
+}
+
+func TestSmoother_Add_Interval(t *testing.T) {
+	start := time.Now()
+	s := New(time.Second)
+	// 5 bearings a second for 3 seconds give a bearing a second
+	var got []*types.Measurement
+	for i := 0; i < 15; i++ {
+		if m := s.Add(&types.Measurement{Timestamp: start.Add(time.Duration(i) * 200 * time.Millisecond), Station: "car1", Bearing: 10 + i}); m != nil {
+			got = append(got, m)
+		}
+	}
+	if len(got) != 3 {
+		t.Fatalf("Add() gave %d bearings, want 3", len(got))
+	}
+	// the last one combines the bearings since the previous one
+	if got[2].Bearing != 18 || !got[2].Timestamp.Equal(start.Add(2*time.Second)) {
+		t.Errorf("Add() = %+v, want a bearing of 18 at 2 seconds", got[2])
+	}
+	if s.Add(&types.Measurement{Timestamp: start.Add(time.Second), Station: "car1", Bearing: 10}) != nil {
+		t.Errorf("Add() gave a bearing for a late measurement within the interval")
+	}
+}
+
+func TestSmoother_Add_OutOfOrder(t *testing.T) {
+	start := time.Now()
+	s := New(time.Second)
+	s.Interval = 0
+	s.Add(&types.Measurement{Timestamp: start.Add(time.Second), Station: "car1", Bearing: 20})
+	got := s.Add(&types.Measurement{Timestamp: start, Station: "car1", Bearing: 10})
+	if got.Bearing != 10 {
+		t.Errorf("Add() = %+v, combined with a later bearing", got)
+	}
+	got = s.Add(&types.Measurement{Timestamp: start.Add(1500 * time.Millisecond), Station: "car1", Bearing: 30})
+	if got.Bearing != 25 {
+		t.Errorf("Add() = %+v, want the late bearing to be expired", got)
+	}
+}
+
+func TestSmoother_Median(t *testing.T) {
+	start := time.Now()
+	s := New(time.Minute)
+	s.Interval = 0
+	s.Median = true
+	var got *types.Measurement
+	for _, bearing := range []int{10, 12, 200, 11, 11} {
+		got = s.Add(&types.Measurement{Timestamp: start, Station: "car1", Bearing: bearing})
+	}
+	if got.Bearing != 11 {
+		t.Errorf("Add() = %v, want a bearing of 11", got)
+	}
+	// the outlier of 200 degrees does not inflate the spread
+	if math.Abs(got.Spread-1.4826) > 0.01 {
+		t.Errorf("Add() = %v, want a spread of 1.48", got)
+	}
+}
@@ -0,0 +1,178 @@
+package status
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"sort"
+	"sync"
+	"time"
+)
+
+// State tells whether a station is still sending
+type State string
+
+const (
+	Online  State = "online"
+	Stale   State = "stale"
+	Offline State = "offline"
+)
+
+// Station is the health of a station
+type Station struct {
+	Station  string    `json:"station"`
+	State    State     `json:"state"`
+	LastSeen time.Time `json:"last_seen"`
+	Rate     float64   `json:"rate"`    // measurements per minute
+	Bearing  *int      `json:"bearing"` // of the last measurement, nil when there wasn't any
+	FixAge   *float64  `json:"fix_age"` // seconds since the last GPS fix, nil when unknown
+	Errors   int       `json:"errors"`  // ingest errors in the window
+}
+
+// Monitor combines the reports of the receivers with the stored measurements and positions,
+// so stations of receivers that don't report are monitored too
+type Monitor struct {
+	Database     database.Database
+	Window       time.Duration // how far back to look for stations
+	RateWindow   time.Duration // the period the message rate is averaged over
+	StaleAfter   time.Duration // a station is stale when it hasn't sent anything for this long
+	OfflineAfter time.Duration // and offline after this long
+	MaxAge       time.Duration // how long a computed status is served before the database is queried again
+	now          func() time.Time
+
+	mutex    sync.Mutex
+	cached   []*Station
+	computed time.Time
+}
+
+func NewMonitor(db database.Database) *Monitor {
+	return &Monitor{
+		Database:     db,
+		Window:       time.Hour,
+		RateWindow:   5 * time.Minute,
+		StaleAfter:   time.Minute,
+		OfflineAfter: 5 * time.Minute,
+		MaxAge:       10 * time.Second,
+		now:          time.Now,
+	}
+}
+
+// Status returns the health of each station that was seen in the window, sorted by station.
+// The result is shared by the callers within MaxAge, so the status poll and the API don't each query the database
+func (m *Monitor) Status() ([]*Station, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if !m.computed.IsZero() && m.now().Sub(m.computed) < m.MaxAge {
+		return m.cached, nil
+	}
+	stations, err := m.compute()
+	if err != nil {
+		return nil, err
+	}
+	m.cached, m.computed = stations, m.now()
+	return stations, nil
+}
+
+func (m *Monitor) compute() ([]*Station, error) {
+	reports, err := m.Database.GetStatus(m.Window)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := m.Database.GetLines(m.Window)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := m.Database.GetPositions(m.Window)
+	if err != nil {
+		return nil, err
+	}
+	return m.evaluate(reports, lines, positions), nil
+}
+
+func (m *Monitor) evaluate(reports []*types.Status, lines []*types.Line, positions []*types.Position) []*Station {
+	now := m.now()
+	stations := map[string]*Station{}
+	get := func(name string) *Station {
+		station, found := stations[name]
+		if !found {
+			station = &Station{Station: name}
+			stations[name] = station
+		}
+		return station
+	}
+
+	// the rate comes from the reports when there are any, as they include measurements that weren't stored
+	reported := map[string]int{}
+	lastReport := map[string]*types.Status{}
+	for _, report := range reports {
+		station := get(report.Station)
+		station.Errors += report.Errors
+		if now.Sub(report.Timestamp) <= m.RateWindow {
+			reported[report.Station] += report.Messages
+		}
+		if !report.LastSeen.IsZero() && report.LastSeen.After(station.LastSeen) {
+			station.LastSeen = report.LastSeen
+			bearing := report.Bearing
+			station.Bearing = &bearing
+		}
+		if last := lastReport[report.Station]; last == nil || report.Timestamp.After(last.Timestamp) {
+			lastReport[report.Station] = report
+		}
+	}
+
+	stored := map[string]int{}
+	for _, line := range lines {
+		station := get(line.Station)
+		if now.Sub(line.Timestamp) <= m.RateWindow {
+			stored[line.Station]++
+		}
+		if line.Timestamp.After(station.LastSeen) {
+			station.LastSeen = line.Timestamp
+			bearing := line.Bearing
+			station.Bearing = &bearing
+		}
+	}
+
+	lastPosition := map[string]time.Time{}
+	for _, position := range positions {
+		get(position.Station)
+		if position.Longitude == 0 && position.Latitude == 0 {
+			continue
+		}
+		if position.Timestamp.After(lastPosition[position.Station]) {
+			lastPosition[position.Station] = position.Timestamp
+		}
+	}
+
+	var result []*Station
+	for name, station := range stations {
+		if count, found := reported[name]; found {
+			station.Rate = float64(count) / m.RateWindow.Minutes()
+		} else {
+			station.Rate = float64(stored[name]) / m.RateWindow.Minutes()
+		}
+
+		if report := lastReport[name]; report != nil && report.FixAge >= 0 {
+			age := report.FixAge + now.Sub(report.Timestamp).Seconds()
+			station.FixAge = &age
+		} else if at, found := lastPosition[name]; found {
+			age := now.Sub(at).Seconds()
+			station.FixAge = &age
+		}
+		// a station that only sends its position is alive too
+		if at := lastPosition[name]; at.After(station.LastSeen) {
+			station.LastSeen = at
+		}
+
+		switch silent := now.Sub(station.LastSeen); {
+		case station.LastSeen.IsZero() || silent >= m.OfflineAfter:
+			station.State = Offline
+		case silent >= m.StaleAfter:
+			station.State = Stale
+		default:
+			station.State = Online
+		}
+		result = append(result, station)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Station < result[j].Station })
+	return result
+}
@@ -0,0 +1,70 @@
+package status
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMonitor_Status(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	db := &databasetest.Database{}
+	// car1 reports, and sent 10 measurements in the last 5 minutes of which 8 were stored
+	db.Statuses = []*types.Status{
+		{Timestamp: now.Add(-20 * time.Minute), Station: "car1", LastSeen: now.Add(-20 * time.Minute), Messages: 50, Errors: 1, Bearing: 10, FixAge: 1},
+		{Timestamp: now.Add(-2 * time.Minute), Station: "car1", LastSeen: now.Add(-2 * time.Minute), Messages: 6, Errors: 2, Bearing: 20, FixAge: 1},
+		{Timestamp: now.Add(-10 * time.Second), Station: "car1", LastSeen: now.Add(-15 * time.Second), Messages: 4, Bearing: 30, FixAge: 0.5},
+	}
+	for i := 0; i < 8; i++ {
+		db.Lines = append(db.Lines, &types.Line{Position: types.Position{Timestamp: now.Add(-time.Duration(20+i*30) * time.Second), Station: "car1", Longitude: 5, Latitude: 52}, Bearing: 30})
+	}
+	// car2 doesn't report, its last bearing was 2 minutes ago and it still sends its position
+	db.Lines = append(db.Lines,
+		&types.Line{Position: types.Position{Timestamp: now.Add(-2 * time.Minute), Station: "car2", Longitude: 5.1, Latitude: 52.1}, Bearing: 200},
+		&types.Line{Position: types.Position{Timestamp: now.Add(-4 * time.Minute), Station: "car2", Longitude: 5.1, Latitude: 52.1}, Bearing: 190},
+	)
+	db.Positions = []*types.Position{
+		{Timestamp: now.Add(-2 * time.Minute), Station: "car2", Longitude: 5.1, Latitude: 52.1},
+		{Timestamp: now.Add(-30 * time.Second), Station: "car2", Longitude: 5.1, Latitude: 52.1},
+	}
+	// car3 went silent, and its last measurement had no position
+	db.Lines = append(db.Lines, &types.Line{Position: types.Position{Timestamp: now.Add(-10 * time.Minute), Station: "car3"}, Bearing: 45})
+	db.Positions = append(db.Positions, &types.Position{Timestamp: now.Add(-10 * time.Minute), Station: "car3"})
+
+	monitor := NewMonitor(db)
+	monitor.now = func() time.Time { return now }
+	stations, err := monitor.Status()
+	if err != nil {
+		t.Fatalf("Status() returned error: %e", err)
+	}
+	if len(stations) != 3 {
+		t.Fatalf("expected 3 stations, got %d", len(stations))
+	}
+
+	car1, car2, car3 := stations[0], stations[1], stations[2]
+	if car1.State != Online || !car1.LastSeen.Equal(now.Add(-15*time.Second)) || *car1.Bearing != 30 || car1.Errors != 3 {
+		t.Errorf("unexpected status %+v", *car1)
+	}
+	if math.Abs(car1.Rate-2) > 1e-9 || math.Abs(*car1.FixAge-10.5) > 1e-9 {
+		t.Errorf("expected 2 per minute and a fix of 10.5 seconds ago, got %f, %f", car1.Rate, *car1.FixAge)
+	}
+	if car2.State != Online || *car2.Bearing != 200 || math.Abs(car2.Rate-0.4) > 1e-9 || *car2.FixAge != 30 || car2.Errors != 0 {
+		t.Errorf("unexpected status %+v", *car2)
+	}
+	if car3.State != Offline || *car3.Bearing != 45 || car3.FixAge != nil || car3.Rate != 0 {
+		t.Errorf("unexpected status %+v", *car3)
+	}
+
+	// the status is served from the cache within MaxAge
+	monitor.StaleAfter = 30 * time.Second
+	if again, _ := monitor.Status(); db.LineQueries() != 1 || again[1].State != Online {
+		t.Errorf("expected the cached status, queried the lines %d times", db.LineQueries())
+	}
+	now = now.Add(monitor.MaxAge)
+	stations, _ = monitor.Status()
+	if stations[0].State != Online || stations[1].State != Stale {
+		t.Errorf("unexpected states %s, %s", stations[0].State, stations[1].State)
+	}
+}
@@ -0,0 +1,123 @@
+// Package status tracks whether the stations are alive, from reports of the receivers and what they stored
+package status
+
+import (
+	"github.com/apex/log"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/position"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Recorder counts the measurements and errors of the stations of a receiver, and stores a report every interval
+type Recorder struct {
+	Database database.Database
+	Position position.Provider // optional, the GPS of the receiver for the fix age
+	Interval time.Duration
+	mutex    sync.Mutex
+	stations map[string]*counter
+	now      func() time.Time
+}
+
+type counter struct {
+	lastSeen time.Time
+	bearing  int
+	messages int
+	errors   int
+}
+
+func NewRecorder(db database.Database) *Recorder {
+	return &Recorder{
+		Database: db,
+		Interval: 10 * time.Second,
+		stations: map[string]*counter{},
+		now:      time.Now,
+	}
+}
+
+// Measurement records a measurement that was received
+func (r *Recorder) Measurement(m *types.Measurement) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	c := r.counter(m.Station)
+	c.messages++
+	if !m.Timestamp.Before(c.lastSeen) {
+		c.lastSeen = m.Timestamp
+		c.bearing = m.Bearing
+	}
+}
+
+// Error records a measurement of the station that couldn't be parsed or stored
+func (r *Recorder) Error(station string) {
+	if station == "" {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.counter(station).errors++
+}
+
+func (r *Recorder) counter(station string) *counter {
+	c, found := r.stations[station]
+	if !found {
+		c = &counter{}
+		r.stations[station] = c
+	}
+	return c
+}
+
+// Start stores the reports every interval, and keeps going when storing fails
+func (r *Recorder) Start() {
+	for {
+		time.Sleep(r.Interval)
+		if err := r.Flush(); err != nil {
+			log.WithError(err).Error("failed to store station status")
+		}
+	}
+}
+
+// Flush stores a report for each station that was seen since the last flush, and resets the counts
+func (r *Recorder) Flush() error {
+	reports := r.Reports()
+	for _, report := range reports {
+		if err := r.Database.AddStatus(report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reports returns the current report of each station that sent something since the last call, sorted by station,
+// and resets the counts. Stations that stayed silent are forgotten, so they don't add a row every interval
+func (r *Recorder) Reports() []*types.Status {
+	fixAge := -1.0
+	if r.Position != nil {
+		if fix := r.Position.Position(); fix != nil {
+			fixAge = r.now().Sub(fix.Timestamp).Seconds()
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var reports []*types.Status
+	for station, c := range r.stations {
+		if c.messages == 0 && c.errors == 0 {
+			delete(r.stations, station)
+			continue
+		}
+		reports = append(reports, &types.Status{
+			Timestamp: r.now(),
+			Station:   station,
+			LastSeen:  c.lastSeen,
+			Messages:  c.messages,
+			Errors:    c.errors,
+			Bearing:   c.bearing,
+			FixAge:    fixAge,
+		})
+		c.messages, c.errors = 0, 0
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Station < reports[j].Station })
+	return reports
+}
@@ -0,0 +1,48 @@
+package status
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/position/positiontest"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	db := &databasetest.Database{}
+	recorder := NewRecorder(db)
+	recorder.now = func() time.Time { return now }
+
+	recorder.Measurement(&types.Measurement{Timestamp: now.Add(-2 * time.Second), Station: "car1", Bearing: 90})
+	recorder.Measurement(&types.Measurement{Timestamp: now.Add(-3 * time.Second), Station: "car1", Bearing: 80}) // late
+	recorder.Error("car1")
+	recorder.Error("car2")
+	recorder.Error("")
+	if err := recorder.Flush(); err != nil {
+		t.Fatalf("Flush() returned error: %e", err)
+	}
+	if len(db.Statuses) != 2 {
+		t.Fatalf("expected a report per station, got %d", len(db.Statuses))
+	}
+	car1, car2 := db.Statuses[0], db.Statuses[1]
+	if car1.Station != "car1" || car1.Messages != 2 || car1.Errors != 1 || car1.Bearing != 90 ||
+		!car1.LastSeen.Equal(now.Add(-2*time.Second)) || car1.FixAge != -1 || !car1.Timestamp.Equal(now) {
+		t.Errorf("unexpected report %+v", *car1)
+	}
+	if car2.Station != "car2" || car2.Messages != 0 || car2.Errors != 1 || !car2.LastSeen.IsZero() {
+		t.Errorf("unexpected report %+v", *car2)
+	}
+
+	// the counts start over, and silent stations are forgotten
+	if reports := recorder.Reports(); len(reports) != 0 {
+		t.Errorf("expected no reports for silent stations, got %d", len(reports))
+	}
+	recorder.Position = positiontest.Fixed{Timestamp: now.Add(-1500 * time.Millisecond)}
+	recorder.Measurement(&types.Measurement{Timestamp: now, Station: "car2", Bearing: 70})
+	reports := recorder.Reports()
+	if len(reports) != 1 || reports[0].Station != "car2" || reports[0].Messages != 1 || reports[0].Errors != 0 ||
+		reports[0].Bearing != 70 || reports[0].FixAge != 1.5 {
+		t.Errorf("unexpected reports %+v", reports)
+	}
+}
@@ -0,0 +1,118 @@
+package tiles
+
+import (
+	"database/sql"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3" // sqlite driver
+	"os"
+)
+
+const mbtilesSchema = `
+CREATE TABLE IF NOT EXISTS metadata (name TEXT PRIMARY KEY, value TEXT);
+CREATE TABLE IF NOT EXISTS tiles (zoom_level INTEGER, tile_column INTEGER, tile_row INTEGER, tile_data BLOB);
+CREATE UNIQUE INDEX IF NOT EXISTS tile_index ON tiles (zoom_level, tile_column, tile_row);
+`
+
+// MBTiles is a tile set in an SQLite database, as described by https://github.com/mapbox/mbtiles-spec.
+// Rows are stored in the TMS scheme, with y counting from the south.
+type MBTiles struct {
+	db     *sql.DB
+	format string
+}
+
+// OpenMBTiles opens an MBTiles file read-only, to serve it. The tiles table can be a view, like in the
+// deduplicated layout of mbutil and OpenMapTiles.
+func OpenMBTiles(path string) (*MBTiles, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return openMBTiles(path, "file:"+path+"?mode=ro", "")
+}
+
+// CreateMBTiles opens an MBTiles file to store tiles in, creating it when it doesn't exist
+func CreateMBTiles(path string) (*MBTiles, error) {
+	return openMBTiles(path, path, mbtilesSchema)
+}
+
+func openMBTiles(path, dataSource, schema string) (*MBTiles, error) {
+	db, err := sql.Open("sqlite3", dataSource)
+	if err != nil {
+		return nil, err
+	}
+	if schema != "" {
+		if _, err := db.Exec(schema); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to open %s: %v", path, err)
+		}
+	}
+	m := &MBTiles{db: db}
+	m.format, err = m.Metadata("format")
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	if m.format == "" {
+		m.format = "png" // the default of the spec
+	}
+	return m, nil
+}
+
+func (m *MBTiles) Tile(z uint8, x, y uint32) ([]byte, string, error) {
+	if !Valid(z, x, y) {
+		return nil, "", ErrNotFound
+	}
+	var data []byte
+	err := m.db.QueryRow("SELECT tile_data FROM tiles WHERE zoom_level=$1 AND tile_column=$2 AND tile_row=$3", z, x, flip(z, y)).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, "", ErrNotFound
+	}
+	return data, m.format, err
+}
+
+func (m *MBTiles) Format() string {
+	return m.format
+}
+
+// Has returns whether the tile is stored
+func (m *MBTiles) Has(z uint8, x, y uint32) (bool, error) {
+	var count int
+	err := m.db.QueryRow("SELECT count(*) FROM tiles WHERE zoom_level=$1 AND tile_column=$2 AND tile_row=$3", z, x, flip(z, y)).Scan(&count)
+	return count > 0, err
+}
+
+// Put stores a tile, replacing an existing one
+func (m *MBTiles) Put(z uint8, x, y uint32, data []byte) error {
+	if !Valid(z, x, y) {
+		return fmt.Errorf("invalid tile %d/%d/%d", z, x, y)
+	}
+	_, err := m.db.Exec("INSERT OR REPLACE INTO tiles (zoom_level, tile_column, tile_row, tile_data) VALUES ($1, $2, $3, $4)", z, x, flip(z, y), data)
+	return err
+}
+
+// Metadata returns a value of the metadata table, or an empty string when it isn't set
+func (m *MBTiles) Metadata(name string) (string, error) {
+	var value string
+	err := m.db.QueryRow("SELECT value FROM metadata WHERE name=$1", name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+// SetMetadata sets a value of the metadata table
+func (m *MBTiles) SetMetadata(name, value string) error {
+	_, err := m.db.Exec("INSERT OR REPLACE INTO metadata (name, value) VALUES ($1, $2)", name, value)
+	if err == nil && name == "format" {
+		m.format = value
+	}
+	return err
+}
+
+func (m *MBTiles) Close() error {
+	return m.db.Close()
+}
+
+// flip converts between the XYZ and TMS row numbering
+func flip(z uint8, y uint32) uint32 {
+	return uint32(1<<z) - 1 - y
+}
@@ -0,0 +1,111 @@
+package tiles
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMBTiles(t *testing.T) {
+	directory, err := ioutil.TempDir("", "tiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+	path := filepath.Join(directory, "osm.mbtiles")
+	m, err := CreateMBTiles(path)
+	if err != nil {
+		t.Fatalf("CreateMBTiles() returned error: %e", err)
+	}
+	if m.Format() != "png" {
+		t.Errorf("expected png without a format, got %s", m.Format())
+	}
+	if err := m.SetMetadata("format", "jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Put(12, 2104, 1350, []byte("tile")); err != nil {
+		t.Fatalf("Put() returned error: %e", err)
+	}
+	if err := m.Put(1, 2, 0, []byte("tile")); err == nil {
+		t.Error("expected an error for a tile outside the grid")
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() returned error: %e", err)
+	}
+	m = source.(*MBTiles)
+	defer m.Close()
+	if m.Format() != "jpg" {
+		t.Errorf("expected the format of the metadata, got %s", m.Format())
+	}
+	data, _, err := m.Tile(12, 2104, 1350)
+	if err != nil || string(data) != "tile" {
+		t.Errorf("Tile() = %s, %v", data, err)
+	}
+	if _, _, err := m.Tile(12, 2104, 1351); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound for a missing tile, got %v", err)
+	}
+
+	// rows are stored from the south
+	var row uint32
+	if err := m.db.QueryRow("SELECT tile_row FROM tiles").Scan(&row); err != nil || row != 4095-1350 {
+		t.Errorf("unexpected row %d: %v", row, err)
+	}
+	if has, err := m.Has(12, 2104, 1350); !has || err != nil {
+		t.Errorf("Has() = %v, %v", has, err)
+	}
+}
+
+func TestOpenMBTiles_Deduplicated(t *testing.T) {
+	directory, err := ioutil.TempDir("", "tiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+	path := filepath.Join(directory, "openmaptiles.mbtiles")
+
+	// the layout of mbutil and OpenMapTiles, where tiles is a view over the map and the images
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`
+CREATE TABLE metadata (name TEXT, value TEXT);
+CREATE TABLE map (zoom_level INTEGER, tile_column INTEGER, tile_row INTEGER, tile_id TEXT);
+CREATE TABLE images (tile_data BLOB, tile_id TEXT);
+CREATE VIEW tiles AS SELECT map.zoom_level AS zoom_level, map.tile_column AS tile_column, map.tile_row AS tile_row,
+	images.tile_data AS tile_data FROM map JOIN images ON images.tile_id = map.tile_id;
+INSERT INTO metadata VALUES ('format', 'pbf');
+INSERT INTO map VALUES (12, 2104, 2745, 'a');
+INSERT INTO images VALUES ('tile', 'a');
+`); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+
+	m, err := OpenMBTiles(path)
+	if err != nil {
+		t.Fatalf("OpenMBTiles() returned error: %e", err)
+	}
+	defer m.Close()
+	data, format, err := m.Tile(12, 2104, 1350)
+	if err != nil || string(data) != "tile" || format != "pbf" {
+		t.Errorf("Tile() = %s, %s, %v", data, format, err)
+	}
+	if err := m.Put(12, 2104, 1351, []byte("tile")); err == nil {
+		t.Error("expected an error for storing in a file that is opened read-only")
+	}
+
+	if _, err := OpenMBTiles(filepath.Join(directory, "missing.mbtiles")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	if _, err := os.Stat(filepath.Join(directory, "missing.mbtiles")); !os.IsNotExist(err) {
+		t.Errorf("opening a missing file created it: %v", err)
+	}
+}
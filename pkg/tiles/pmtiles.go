@@ -0,0 +1,273 @@
+package tiles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+const pmtilesHeaderLength = 127
+
+// compression types of PMTiles
+const (
+	pmtilesCompressionUnknown = 0
+	pmtilesCompressionNone    = 1
+	pmtilesCompressionGzip    = 2
+)
+
+// PMTiles is a tile set in a single file, as described by https://github.com/protomaps/PMTiles/blob/main/spec/v3/spec.md.
+// Only version 3 with uncompressed or gzip compressed directories and tiles is supported.
+type PMTiles struct {
+	file   io.ReaderAt
+	closer io.Closer
+	header pmtilesHeader
+	root   []pmtilesEntry
+}
+
+type pmtilesHeader struct {
+	rootOffset, rootLength    uint64
+	leavesOffset              uint64
+	dataOffset                uint64
+	internalCompression       uint8
+	tileCompression, tileType uint8
+	minZoom, maxZoom          uint8
+}
+
+type pmtilesEntry struct {
+	tileID    uint64
+	offset    uint64
+	length    uint32
+	runLength uint32 // 0 for a leaf directory
+}
+
+// OpenPMTiles opens a PMTiles file
+func OpenPMTiles(path string) (*PMTiles, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := NewPMTiles(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	p.closer = file
+	return p, nil
+}
+
+// NewPMTiles reads the header and root directory of a PMTiles archive
+func NewPMTiles(file io.ReaderAt) (*PMTiles, error) {
+	data := make([]byte, pmtilesHeaderLength)
+	if _, err := file.ReadAt(data, 0); err != nil {
+		return nil, err
+	}
+	if string(data[0:7]) != "PMTiles" {
+		return nil, errors.New("not a PMTiles file")
+	}
+	if data[7] != 3 {
+		return nil, fmt.Errorf("unsupported PMTiles version %d", data[7])
+	}
+	p := &PMTiles{
+		file: file,
+		header: pmtilesHeader{
+			rootOffset:          binary.LittleEndian.Uint64(data[8:16]),
+			rootLength:          binary.LittleEndian.Uint64(data[16:24]),
+			leavesOffset:        binary.LittleEndian.Uint64(data[40:48]),
+			dataOffset:          binary.LittleEndian.Uint64(data[56:64]),
+			internalCompression: data[97],
+			tileCompression:     data[98],
+			tileType:            data[99],
+			minZoom:             data[100],
+			maxZoom:             data[101],
+		},
+	}
+	if !supportedCompression(p.header.internalCompression) || !supportedCompression(p.header.tileCompression) {
+		return nil, errors.New("unsupported PMTiles compression")
+	}
+	var err error
+	p.root, err = p.directory(p.header.rootOffset, p.header.rootLength)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *PMTiles) Tile(z uint8, x, y uint32) ([]byte, string, error) {
+	if !Valid(z, x, y) || z < p.header.minZoom || z > p.header.maxZoom {
+		return nil, "", ErrNotFound
+	}
+	id := tileID(z, x, y)
+	directory := p.root
+	// the spec allows a root directory and up to three levels of leaf directories
+	for depth := 0; depth < 4; depth++ {
+		entry := findEntry(directory, id)
+		if entry == nil {
+			return nil, "", ErrNotFound
+		}
+		if entry.runLength > 0 {
+			data, err := p.read(p.header.dataOffset+entry.offset, uint64(entry.length))
+			if err != nil {
+				return nil, "", err
+			}
+			data, err = decompress(p.header.tileCompression, data)
+			return data, p.Format(), err
+		}
+		var err error
+		directory, err = p.directory(p.header.leavesOffset+entry.offset, uint64(entry.length))
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return nil, "", ErrNotFound
+}
+
+func (p *PMTiles) Format() string {
+	switch p.header.tileType {
+	case 1:
+		return "pbf"
+	case 2:
+		return "png"
+	case 3:
+		return "jpg"
+	case 4:
+		return "webp"
+	case 5:
+		return "avif"
+	}
+	return ""
+}
+
+func (p *PMTiles) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+	return p.closer.Close()
+}
+
+func (p *PMTiles) read(offset, length uint64) ([]byte, error) {
+	data := make([]byte, length)
+	if _, err := p.file.ReadAt(data, int64(offset)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// directory reads and decodes a directory, which lists the tile IDs, run lengths, lengths and offsets of its entries
+func (p *PMTiles) directory(offset, length uint64) ([]pmtilesEntry, error) {
+	data, err := p.read(offset, length)
+	if err != nil {
+		return nil, err
+	}
+	data, err = decompress(p.header.internalCompression, data)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := bytes.NewReader(data)
+	count, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(len(data)) {
+		return nil, errors.New("invalid PMTiles directory")
+	}
+	entries := make([]pmtilesEntry, count)
+	var id uint64
+	for i := range entries {
+		delta, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, err
+		}
+		id += delta
+		entries[i].tileID = id
+	}
+	for i := range entries {
+		runLength, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, err
+		}
+		entries[i].runLength = uint32(runLength)
+	}
+	for i := range entries {
+		length, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, err
+		}
+		entries[i].length = uint32(length)
+	}
+	for i := range entries {
+		offset, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, err
+		}
+		if offset == 0 && i > 0 {
+			// the data directly follows that of the previous entry
+			entries[i].offset = entries[i-1].offset + uint64(entries[i-1].length)
+		} else {
+			entries[i].offset = offset - 1
+		}
+	}
+	return entries, nil
+}
+
+// findEntry returns the entry with the tile, or the leaf directory that may have it
+func findEntry(entries []pmtilesEntry, id uint64) *pmtilesEntry {
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].tileID > id }) - 1
+	if i < 0 {
+		return nil
+	}
+	entry := &entries[i]
+	if entry.runLength == 0 || id < entry.tileID+uint64(entry.runLength) {
+		return entry
+	}
+	return nil
+}
+
+func supportedCompression(compression uint8) bool {
+	return compression == pmtilesCompressionUnknown || compression == pmtilesCompressionNone || compression == pmtilesCompressionGzip
+}
+
+func decompress(compression uint8, data []byte) ([]byte, error) {
+	if compression != pmtilesCompressionGzip {
+		return data, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// tileID numbers the tiles of all zoom levels along a Hilbert curve per level, starting with the lowest level
+func tileID(z uint8, x, y uint32) uint64 {
+	var id uint64
+	for level := uint8(0); level < z; level++ {
+		id += 1 << (2 * level)
+	}
+	for s := uint32(1<<z) / 2; s > 0; s /= 2 {
+		var rx, ry uint32
+		if x&s > 0 {
+			rx = 1
+		}
+		if y&s > 0 {
+			ry = 1
+		}
+		id += uint64(s) * uint64(s) * uint64((3*rx)^ry)
+		// rotate the quadrant
+		if ry == 0 {
+			if rx == 1 {
+				x = s - 1 - x
+				y = s - 1 - y
+			}
+			x, y = y, x
+		}
+	}
+	return id
+}
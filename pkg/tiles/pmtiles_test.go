@@ -0,0 +1,156 @@
+package tiles
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTileID(t *testing.T) {
+	tests := []struct {
+		z    uint8
+		x, y uint32
+		want uint64
+	}{
+		{0, 0, 0, 0},
+		{1, 0, 0, 1},
+		{1, 0, 1, 2},
+		{1, 1, 1, 3},
+		{1, 1, 0, 4},
+		{2, 0, 0, 5},
+		{2, 3, 0, 20},
+		{12, 0, 0, 5592405},
+	}
+	for _, tt := range tests {
+		if got := tileID(tt.z, tt.x, tt.y); got != tt.want {
+			t.Errorf("tileID(%d, %d, %d) = %d, want %d", tt.z, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestPMTiles(t *testing.T) {
+	directory, err := ioutil.TempDir("", "tiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+	path := filepath.Join(directory, "osm.pmtiles")
+	if err := ioutil.WriteFile(path, testPMTiles(t), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() returned error: %e", err)
+	}
+	p := source.(*PMTiles)
+	defer p.Close()
+	if p.Format() != "png" {
+		t.Errorf("unexpected format %s", p.Format())
+	}
+
+	tests := []struct {
+		z    uint8
+		x, y uint32
+		want string
+	}{
+		{0, 0, 0, "world"},
+		{1, 0, 0, "ocean"},
+		{1, 0, 1, "ocean"}, // run length 2
+		{1, 1, 1, ""},
+		{2, 3, 0, "leaf"},
+		{2, 3, 1, ""},
+		{3, 0, 0, ""},
+	}
+	for _, tt := range tests {
+		data, _, err := p.Tile(tt.z, tt.x, tt.y)
+		if tt.want == "" {
+			if err != ErrNotFound {
+				t.Errorf("Tile(%d, %d, %d) expected ErrNotFound, got %s, %v", tt.z, tt.x, tt.y, data, err)
+			}
+			continue
+		}
+		if err != nil || string(data) != tt.want {
+			t.Errorf("Tile(%d, %d, %d) = %s, %v, want %s", tt.z, tt.x, tt.y, data, err, tt.want)
+		}
+	}
+
+	if _, err := NewPMTiles(bytes.NewReader(make([]byte, pmtilesHeaderLength))); err == nil {
+		t.Error("expected an error for a file that isn't PMTiles")
+	}
+}
+
+// testPMTiles returns an archive with gzip compressed directories, of which the root points to a leaf directory for zoom 2
+func testPMTiles(t *testing.T) []byte {
+	tileData := []byte("worldoceanleaf")
+	leaf := compress(t, encodeDirectory([]pmtilesEntry{{tileID: 20, offset: 10, length: 4, runLength: 1}}))
+	root := compress(t, encodeDirectory([]pmtilesEntry{
+		{tileID: 0, offset: 0, length: 5, runLength: 1},
+		{tileID: 1, offset: 5, length: 5, runLength: 2},
+		{tileID: 5, offset: 0, length: uint32(len(leaf)), runLength: 0},
+	}))
+
+	header := make([]byte, pmtilesHeaderLength)
+	copy(header, "PMTiles")
+	header[7] = 3
+	rootOffset := uint64(pmtilesHeaderLength)
+	leavesOffset := rootOffset + uint64(len(root))
+	dataOffset := leavesOffset + uint64(len(leaf))
+	binary.LittleEndian.PutUint64(header[8:], rootOffset)
+	binary.LittleEndian.PutUint64(header[16:], uint64(len(root)))
+	binary.LittleEndian.PutUint64(header[40:], leavesOffset)
+	binary.LittleEndian.PutUint64(header[48:], uint64(len(leaf)))
+	binary.LittleEndian.PutUint64(header[56:], dataOffset)
+	binary.LittleEndian.PutUint64(header[64:], uint64(len(tileData)))
+	header[97] = pmtilesCompressionGzip
+	header[98] = pmtilesCompressionNone
+	header[99] = 2 // png
+	header[100] = 0
+	header[101] = 2
+
+	return bytes.Join([][]byte{header, root, leaf, tileData}, nil)
+}
+
+func encodeDirectory(entries []pmtilesEntry) []byte {
+	buffer := make([]byte, binary.MaxVarintLen64)
+	var data []byte
+	put := func(value uint64) {
+		n := binary.PutUvarint(buffer, value)
+		data = append(data, buffer[:n]...)
+	}
+	put(uint64(len(entries)))
+	var last uint64
+	for _, entry := range entries {
+		put(entry.tileID - last)
+		last = entry.tileID
+	}
+	for _, entry := range entries {
+		put(uint64(entry.runLength))
+	}
+	for _, entry := range entries {
+		put(uint64(entry.length))
+	}
+	for i, entry := range entries {
+		if i > 0 && entry.offset == entries[i-1].offset+uint64(entries[i-1].length) {
+			put(0)
+		} else {
+			put(entry.offset + 1)
+		}
+	}
+	return data
+}
+
+func compress(t *testing.T, data []byte) []byte {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return compressed.Bytes()
+}
@@ -0,0 +1,276 @@
+package tiles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+	"github.com/paulmach/orb/maptile/tilecover"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Seeder fetches the tiles of a hunt area into an MBTiles file, so the map works without coverage.
+// Tiles that are already stored are skipped, so an interrupted seed resumes where it stopped.
+type Seeder struct {
+	Source   Source
+	Target   *MBTiles
+	Rate     float64        // tiles per second fetched from the source, 0 for no limit
+	Progress func(Progress) // optional, called after each tile
+	sleep    func(time.Duration)
+	now      func() time.Time
+	next     time.Time // when the next tile may be fetched
+}
+
+// Progress counts the tiles of a seed
+type Progress struct {
+	Total   int
+	Fetched int
+	Skipped int // already stored
+	Missing int // not available at the source
+	Failed  int
+	Bytes   int64 // of the fetched tiles
+}
+
+// Estimate is the expected download of a seed
+type Estimate struct {
+	Tiles  int   // in the area
+	Stored int   // already in the target
+	Bytes  int64 // of the tiles that aren't stored yet
+}
+
+func NewSeeder(source Source, target *MBTiles) *Seeder {
+	return &Seeder{
+		Source: source,
+		Target: target,
+		sleep:  time.Sleep,
+		now:    time.Now,
+	}
+}
+
+// Cover returns the tiles that touch the area at the zoom levels from minZoom to maxZoom, ordered by z, x and y
+func Cover(area orb.Geometry, minZoom, maxZoom uint8) []maptile.Tile {
+	var tiles []maptile.Tile
+	for z := minZoom; z <= maxZoom && z <= 30; z++ {
+		set := tilecover.Geometry(area, maptile.Zoom(z))
+		level := make([]maptile.Tile, 0, len(set))
+		for tile := range set {
+			level = append(level, tile)
+		}
+		sort.Slice(level, func(i, j int) bool {
+			if level[i].X != level[j].X {
+				return level[i].X < level[j].X
+			}
+			return level[i].Y < level[j].Y
+		})
+		tiles = append(tiles, level...)
+	}
+	return tiles
+}
+
+// Estimate counts the tiles that still have to be fetched, and estimates their size from the average size of
+// the stored tiles, or of up to samples tiles fetched from the source when none are stored yet.
+// With 0 samples the source isn't contacted at all. The samples are fetched at the rate of the seed.
+func (s *Seeder) Estimate(tiles []maptile.Tile, samples int) (*Estimate, error) {
+	estimate := &Estimate{Tiles: len(tiles)}
+	var missing []maptile.Tile
+	for _, tile := range tiles {
+		stored, err := s.Target.Has(uint8(tile.Z), tile.X, tile.Y)
+		if err != nil {
+			return nil, err
+		}
+		if stored {
+			estimate.Stored++
+		} else {
+			missing = append(missing, tile)
+		}
+	}
+	if len(missing) == 0 {
+		return estimate, nil
+	}
+
+	var count, size int64
+	err := s.Target.db.QueryRow("SELECT count(*), coalesce(sum(length(tile_data)), 0) FROM tiles").Scan(&count, &size)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		// spread the samples over the area and the zoom levels
+		for i := 0; i < samples && i < len(missing); i++ {
+			tile := missing[i*len(missing)/minInt(samples, len(missing))]
+			s.limit()
+			data, _, err := s.Source.Tile(uint8(tile.Z), tile.X, tile.Y)
+			if err == ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch a sample tile: %v", err)
+			}
+			count++
+			size += int64(len(data))
+		}
+	}
+	if count > 0 {
+		estimate.Bytes = size * int64(len(missing)) / count
+	}
+	return estimate, nil
+}
+
+// Seed fetches and stores the tiles that aren't stored yet.
+// Failing tiles are counted and skipped, the error is only returned when storing fails.
+func (s *Seeder) Seed(tiles []maptile.Tile) (*Progress, error) {
+	progress := &Progress{Total: len(tiles)}
+	if err := s.metadata(tiles); err != nil {
+		return progress, err
+	}
+
+	for _, tile := range tiles {
+		z := uint8(tile.Z)
+		stored, err := s.Target.Has(z, tile.X, tile.Y)
+		if err != nil {
+			return progress, err
+		}
+		if stored {
+			progress.Skipped++
+			s.report(progress)
+			continue
+		}
+
+		s.limit()
+		data, _, err := s.Source.Tile(z, tile.X, tile.Y)
+		switch {
+		case err == ErrNotFound:
+			progress.Missing++
+		case err != nil:
+			progress.Failed++
+		default:
+			if err := s.Target.Put(z, tile.X, tile.Y, data); err != nil {
+				return progress, err
+			}
+			progress.Fetched++
+			progress.Bytes += int64(len(data))
+		}
+		s.report(progress)
+	}
+	return progress, nil
+}
+
+// limit waits until the next tile may be fetched from the source
+func (s *Seeder) limit() {
+	if s.Rate <= 0 {
+		return
+	}
+	if wait := s.next.Sub(s.now()); wait > 0 {
+		s.sleep(wait)
+	}
+	s.next = s.now().Add(time.Duration(float64(time.Second) / s.Rate))
+}
+
+func (s *Seeder) report(progress *Progress) {
+	if s.Progress != nil {
+		s.Progress(*progress)
+	}
+}
+
+// metadata describes the seeded tile set, extending the zoom levels of an earlier seed
+func (s *Seeder) metadata(tiles []maptile.Tile) error {
+	if len(tiles) == 0 {
+		return nil
+	}
+	minZoom, maxZoom := tiles[0].Z, tiles[len(tiles)-1].Z
+	if value, _ := s.Target.Metadata("minzoom"); value != "" {
+		if z, err := strconv.Atoi(value); err == nil && maptile.Zoom(z) < minZoom {
+			minZoom = maptile.Zoom(z)
+		}
+	}
+	if value, _ := s.Target.Metadata("maxzoom"); value != "" {
+		if z, err := strconv.Atoi(value); err == nil && maptile.Zoom(z) > maxZoom {
+			maxZoom = maptile.Zoom(z)
+		}
+	}
+	metadata := [][2]string{
+		{"name", "OSM-ARDF"},
+		{"type", "baselayer"},
+		{"format", s.Source.Format()},
+		{"minzoom", strconv.Itoa(int(minZoom))},
+		{"maxzoom", strconv.Itoa(int(maxZoom))},
+	}
+	for _, m := range metadata {
+		if err := s.Target.SetMetadata(m[0], m[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseBound parses a bounding box as min longitude, min latitude, max longitude and max latitude, separated by commas
+func ParseBound(s string) (orb.Bound, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 4 {
+		return orb.Bound{}, errors.New("expected min lon,min lat,max lon,max lat")
+	}
+	var values [4]float64
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return orb.Bound{}, fmt.Errorf("invalid coordinate %s", field)
+		}
+		values[i] = value
+	}
+	bound := orb.Bound{Min: orb.Point{values[0], values[1]}, Max: orb.Point{values[2], values[3]}}
+	if bound.Min.Lon() > bound.Max.Lon() || bound.Min.Lat() > bound.Max.Lat() ||
+		bound.Min.Lon() < -180 || bound.Max.Lon() > 180 || bound.Min.Lat() < -85.06 || bound.Max.Lat() > 85.06 {
+		return orb.Bound{}, errors.New("invalid bounding box")
+	}
+	return bound, nil
+}
+
+// ParseArea parses a GeoJSON geometry, feature or feature collection, like a polygon around the hunt
+func ParseArea(data []byte) (orb.Geometry, error) {
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	switch object.Type {
+	case "FeatureCollection":
+		collection, err := geojson.UnmarshalFeatureCollection(data)
+		if err != nil {
+			return nil, err
+		}
+		var area orb.Collection
+		for _, feature := range collection.Features {
+			area = append(area, feature.Geometry)
+		}
+		if len(area) == 0 {
+			return nil, errors.New("no features in the area")
+		}
+		return area, nil
+	case "Feature":
+		feature, err := geojson.UnmarshalFeature(data)
+		if err != nil {
+			return nil, err
+		}
+		return feature.Geometry, nil
+	}
+	geometry, err := geojson.UnmarshalGeometry(data)
+	if err != nil {
+		return nil, err
+	}
+	if geometry.Coordinates == nil {
+		return nil, errors.New("no geometry in the area")
+	}
+	return geometry.Coordinates, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,132 @@
+package tiles
+
+import (
+	"fmt"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCover(t *testing.T) {
+	bound, err := ParseBound("5.1,52.05,5.2,52.1")
+	if err != nil {
+		t.Fatalf("ParseBound() returned error: %e", err)
+	}
+	tiles := Cover(bound, 0, 12)
+	if tiles[0].Z != 0 || tiles[len(tiles)-1].Z != 12 {
+		t.Errorf("unexpected zoom levels %d to %d", tiles[0].Z, tiles[len(tiles)-1].Z)
+	}
+	// a triangle in the box covers fewer tiles
+	triangle, err := ParseArea([]byte(`{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[5.1,52.05],[5.2,52.05],[5.1,52.1],[5.1,52.05]]]},"properties":{}}`))
+	if err != nil {
+		t.Fatalf("ParseArea() returned error: %e", err)
+	}
+	if len(Cover(triangle, 14, 14)) >= len(Cover(bound, 14, 14)) {
+		t.Errorf("expected fewer tiles for the triangle: %d, %d", len(Cover(triangle, 14, 14)), len(Cover(bound, 14, 14)))
+	}
+
+	for _, invalid := range []string{"5.1,52.05,5.2", "5.2,52.05,5.1,52.1", "5.1,x,5.2,52.1", "5,89,6,90"} {
+		if _, err := ParseBound(invalid); err == nil {
+			t.Errorf("expected an error for %s", invalid)
+		}
+	}
+	for _, invalid := range []string{`{"type":"FeatureCollection","features":[]}`, `{"type":"Polygon"}`, `[`} {
+		if _, err := ParseArea([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestSeeder(t *testing.T) {
+	server := newTileServer(13)
+	defer server.Close()
+	directory, err := ioutil.TempDir("", "tiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+	target, err := CreateMBTiles(filepath.Join(directory, "hunt.mbtiles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+
+	seeder := NewSeeder(NewUpstream(server.URL+"/{z}/{x}/{y}.png", ""), target)
+	clock := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	var slept time.Duration
+	seeder.now = func() time.Time { return clock }
+	seeder.sleep = func(d time.Duration) {
+		slept += d
+		clock = clock.Add(d)
+	}
+	seeder.Rate = 10
+	var reports int
+	seeder.Progress = func(Progress) { reports++ }
+
+	tiles := Cover(orb.Bound{Min: orb.Point{5.1, 52.05}, Max: orb.Point{5.2, 52.1}}, 12, 14)
+	// without samples only the tiles are counted, for a dry run
+	estimate, err := seeder.Estimate(tiles, 0)
+	if err != nil || estimate.Tiles != len(tiles) || estimate.Bytes != 0 || server.Requests() != 0 {
+		t.Errorf("unexpected estimate %+v, %v after %d requests", estimate, err, server.Requests())
+	}
+
+	estimate, err = seeder.Estimate(tiles, 3)
+	if err != nil {
+		t.Fatalf("Estimate() returned error: %e", err)
+	}
+	if estimate.Tiles != len(tiles) || estimate.Stored != 0 || estimate.Bytes < int64(len(tiles)*10) || estimate.Bytes > int64(len(tiles)*14) {
+		t.Errorf("unexpected estimate %+v for %d tiles", *estimate, len(tiles))
+	}
+	requests := server.Requests()
+
+	progress, err := seeder.Seed(tiles)
+	if err != nil {
+		t.Fatalf("Seed() returned error: %e", err)
+	}
+	missing := len(Cover(orb.Bound{Min: orb.Point{5.1, 52.05}, Max: orb.Point{5.2, 52.1}}, 14, 14))
+	if progress.Fetched != len(tiles)-missing || progress.Missing != missing || progress.Failed != 0 || progress.Skipped != 0 {
+		t.Errorf("unexpected progress %+v", *progress)
+	}
+	if reports != len(tiles) {
+		t.Errorf("expected a report per tile, got %d", reports)
+	}
+	if server.Requests()-requests != len(tiles) {
+		t.Errorf("expected a request per tile, got %d", server.Requests()-requests)
+	}
+	// the samples of the estimate are fetched at the same rate
+	if want := time.Duration(3+len(tiles)-1) * 100 * time.Millisecond; slept != want {
+		t.Errorf("slept %s, want %s", slept, want)
+	}
+	if format, _ := target.Metadata("format"); format != "png" {
+		t.Errorf("unexpected format %s", format)
+	}
+	tile := maptile.At(orb.Point{5.15, 52.07}, 12)
+	if data, _, err := target.Tile(12, tile.X, tile.Y); err != nil || string(data) != fmt.Sprintf("12/%d/%d", tile.X, tile.Y) {
+		t.Errorf("Tile() = %s, %v", data, err)
+	}
+
+	// resume only fetches what's missing, and estimates from the stored tiles
+	estimate, err = seeder.Estimate(tiles, 3)
+	if err != nil || estimate.Stored != len(tiles)-missing || estimate.Bytes < int64(missing*10) {
+		t.Errorf("unexpected estimate %+v, %v", estimate, err)
+	}
+	requests = server.Requests()
+	progress, err = seeder.Seed(tiles)
+	if err != nil || progress.Skipped != len(tiles)-missing || progress.Missing != missing {
+		t.Errorf("unexpected progress %+v, %v", progress, err)
+	}
+	if server.Requests()-requests != missing {
+		t.Errorf("expected only requests for missing tiles, got %d", server.Requests()-requests)
+	}
+
+	// failing tiles are counted
+	server.Close()
+	progress, err = seeder.Seed(Cover(orb.Point{5.1, 52.05}, 11, 11))
+	if err != nil || progress.Failed != 1 {
+		t.Errorf("unexpected progress %+v, %v", progress, err)
+	}
+}
@@ -0,0 +1,75 @@
+// Package tiles serves map tiles from MBTiles and PMTiles files and from an upstream tile server,
+// so the web server can show the map without a separate tile proxy and without a network connection
+package tiles
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned by a source that doesn't have the requested tile
+var ErrNotFound = errors.New("tile not found")
+
+// Source returns the tiles of a single tile set, addressed as z/x/y in the XYZ (slippy map) scheme
+type Source interface {
+	// Tile returns the tile with its format, as the sources of a chain can differ in format
+	Tile(z uint8, x, y uint32) (data []byte, format string, err error)
+	Format() string // png, jpg, webp or pbf
+}
+
+// Chain returns the tile of the first source that has it, like local files in front of an upstream server
+type Chain []Source
+
+func (c Chain) Tile(z uint8, x, y uint32) ([]byte, string, error) {
+	for _, source := range c {
+		data, format, err := source.Tile(z, x, y)
+		if err == ErrNotFound {
+			continue
+		}
+		return data, format, err
+	}
+	return nil, "", ErrNotFound
+}
+
+// Format returns the format of the first source, the tiles of the other sources can have another format
+func (c Chain) Format() string {
+	if len(c) == 0 {
+		return ""
+	}
+	return c[0].Format()
+}
+
+// Open opens a tile file, based on its extension
+func Open(path string) (Source, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mbtiles":
+		return OpenMBTiles(path)
+	case ".pmtiles":
+		return OpenPMTiles(path)
+	}
+	return nil, fmt.Errorf("unknown tile file type: %s", path)
+}
+
+// ContentType returns the MIME type of a tile format
+func ContentType(format string) string {
+	switch format {
+	case "png":
+		return "image/png"
+	case "jpg", "jpeg":
+		return "image/jpeg"
+	case "webp":
+		return "image/webp"
+	case "avif":
+		return "image/avif"
+	case "pbf", "mvt":
+		return "application/vnd.mapbox-vector-tile"
+	}
+	return "application/octet-stream"
+}
+
+// Valid returns whether x and y are on the grid of zoom level z
+func Valid(z uint8, x, y uint32) bool {
+	return z <= 30 && uint64(x) < 1<<z && uint64(y) < 1<<z
+}
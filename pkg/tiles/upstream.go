@@ -0,0 +1,118 @@
+package tiles
+
+import (
+	"fmt"
+	"github.com/apex/log"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Upstream fetches tiles from a tile server, optionally keeping them in a cache directory as {z}/{x}/{y}.{format}
+type Upstream struct {
+	URL       string // template with {z}, {x} and {y}, like https://tile.openstreetmap.org/{z}/{x}/{y}.png
+	Cache     string // optional cache directory
+	UserAgent string // identifies the application, as tile servers like OpenStreetMap require
+	Client    *http.Client
+	format    string
+}
+
+func NewUpstream(url, cache string) *Upstream {
+	format := strings.TrimPrefix(path.Ext(url), ".")
+	if format == "" || strings.ContainsAny(format, "{}/?") {
+		format = "png"
+	}
+	return &Upstream{
+		URL:       url,
+		Cache:     cache,
+		UserAgent: "OSM-ARDF",
+		Client:    &http.Client{Timeout: 30 * time.Second},
+		format:    format,
+	}
+}
+
+func (u *Upstream) Tile(z uint8, x, y uint32) ([]byte, string, error) {
+	if !Valid(z, x, y) {
+		return nil, "", ErrNotFound
+	}
+	cached := u.cachePath(z, x, y)
+	if cached != "" {
+		if data, err := ioutil.ReadFile(cached); err == nil {
+			return data, u.format, nil
+		}
+	}
+
+	data, err := u.Fetch(z, x, y)
+	if err != nil {
+		return nil, "", err
+	}
+	if cached != "" {
+		// a cache that can't be written to shouldn't take the map down
+		if err := writeFile(cached, data); err != nil {
+			log.WithError(err).WithField("path", cached).Warn("Failed to cache tile")
+		}
+	}
+	return data, u.format, nil
+}
+
+func (u *Upstream) Format() string {
+	return u.format
+}
+
+// Fetch requests a tile from the tile server, bypassing the cache
+func (u *Upstream) Fetch(z uint8, x, y uint32) ([]byte, error) {
+	url := strings.NewReplacer(
+		"{z}", strconv.Itoa(int(z)),
+		"{x}", strconv.FormatUint(uint64(x), 10),
+		"{y}", strconv.FormatUint(uint64(y), 10),
+	).Replace(u.URL)
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", u.UserAgent)
+	response, err := u.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK:
+		return ioutil.ReadAll(response.Body)
+	case http.StatusNotFound, http.StatusNoContent:
+		return nil, ErrNotFound
+	}
+	return nil, fmt.Errorf("tile server returned %s for %s", response.Status, url)
+}
+
+func (u *Upstream) cachePath(z uint8, x, y uint32) string {
+	if u.Cache == "" {
+		return ""
+	}
+	return filepath.Join(u.Cache, strconv.Itoa(int(z)), strconv.FormatUint(uint64(x), 10), fmt.Sprintf("%d.%s", y, u.format))
+}
+
+// writeFile writes through a temporary file, so concurrent requests never read a partial tile
+func writeFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(name), ".tile")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), name)
+}
@@ -0,0 +1,144 @@
+package tiles
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// tileServer is a stand-in for a tile server, which has all tiles of zoom levels up to maxZoom
+type tileServer struct {
+	*httptest.Server
+	maxZoom  int
+	mutex    sync.Mutex
+	requests int
+	agent    string
+}
+
+func newTileServer(maxZoom int) *tileServer {
+	s := &tileServer{maxZoom: maxZoom}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		s.requests++
+		s.agent = r.UserAgent()
+		s.mutex.Unlock()
+		var z, x, y int
+		if _, err := fmt.Sscanf(r.URL.Path, "/%d/%d/%d.png", &z, &x, &y); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if z > s.maxZoom {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = fmt.Fprintf(w, "%d/%d/%d", z, x, y)
+	}))
+	return s
+}
+
+func (s *tileServer) Requests() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests
+}
+
+func TestUpstream(t *testing.T) {
+	server := newTileServer(14)
+	defer server.Close()
+	cache, err := ioutil.TempDir("", "tiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cache)
+
+	upstream := NewUpstream(server.URL+"/{z}/{x}/{y}.png", cache)
+	if upstream.Format() != "png" {
+		t.Errorf("unexpected format %s", upstream.Format())
+	}
+	for i := 0; i < 2; i++ {
+		data, _, err := upstream.Tile(12, 2104, 1350)
+		if err != nil || string(data) != "12/2104/1350" {
+			t.Errorf("Tile() = %s, %v", data, err)
+		}
+	}
+	if server.Requests() != 1 {
+		t.Errorf("expected the second request from the cache, got %d requests", server.Requests())
+	}
+	if server.agent != "OSM-ARDF" {
+		t.Errorf("unexpected user agent %s", server.agent)
+	}
+	if _, err := os.Stat(filepath.Join(cache, "12", "2104", "1350.png")); err != nil {
+		t.Errorf("tile isn't cached: %v", err)
+	}
+	if _, _, err := upstream.Tile(15, 0, 0); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// cached tiles are served without the tile server
+	server.Close()
+	if data, _, err := upstream.Tile(12, 2104, 1350); err != nil || string(data) != "12/2104/1350" {
+		t.Errorf("Tile() while offline = %s, %v", data, err)
+	}
+	if _, _, err := upstream.Tile(12, 2104, 1351); err == nil || err == ErrNotFound {
+		t.Errorf("expected an error while offline, got %v", err)
+	}
+}
+
+func TestUpstream_CacheFailure(t *testing.T) {
+	server := newTileServer(14)
+	defer server.Close()
+	file, err := ioutil.TempFile("", "tiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+	defer os.Remove(file.Name())
+
+	// the cache is a file, so no directory can be made in it
+	upstream := NewUpstream(server.URL+"/{z}/{x}/{y}.png", file.Name())
+	if data, _, err := upstream.Tile(12, 2104, 1350); err != nil || string(data) != "12/2104/1350" {
+		t.Errorf("Tile() = %s, %v, want the fetched tile", data, err)
+	}
+}
+
+func TestChain(t *testing.T) {
+	server := newTileServer(14)
+	defer server.Close()
+	directory, err := ioutil.TempDir("", "tiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+	local, err := CreateMBTiles(filepath.Join(directory, "osm.mbtiles"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+	if err := local.Put(12, 2104, 1350, []byte("local")); err != nil {
+		t.Fatal(err)
+	}
+	if err := local.SetMetadata("format", "jpg"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tiles keep the format of the source that served them
+	chain := Chain{local, NewUpstream(server.URL+"/{z}/{x}/{y}.png", "")}
+	if data, format, err := chain.Tile(12, 2104, 1350); err != nil || string(data) != "local" || format != "jpg" {
+		t.Errorf("expected the local jpg tile, got %s, %s, %v", data, format, err)
+	}
+	if data, format, err := chain.Tile(12, 2104, 1351); err != nil || string(data) != "12/2104/1351" || format != "png" {
+		t.Errorf("expected the upstream png tile, got %s, %s, %v", data, format, err)
+	}
+	if _, _, err := chain.Tile(15, 0, 0); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if chain.Format() != "jpg" || ContentType(chain.Format()) != "image/jpeg" {
+		t.Errorf("unexpected format %s", chain.Format())
+	}
+}
@@ -0,0 +1,192 @@
+// Package tracker follows a moving transmitter by fusing bearings over time in an extended Kalman filter
+// with a constant velocity model.
+package tracker
+
+import (
+	"errors"
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"math"
+	"sort"
+	"time"
+)
+
+type Tracker struct {
+	BearingError     float64       // standard deviation of a bearing, in degrees
+	Acceleration     float64       // standard deviation of the acceleration of the transmitter, in m/s²
+	InitialSpeed     float64       // standard deviation of the unknown initial speed, in m/s
+	HalfLife         time.Duration // age at which the weight of a bearing is halved
+	PredictionStep   time.Duration // time between predicted positions
+	PredictionLength time.Duration // how far ahead to predict
+}
+
+// New returns a tracker with defaults that suit a balloon or car carrying the transmitter
+func New() *Tracker {
+	return &Tracker{
+		BearingError:     5,
+		Acceleration:     0.5,
+		InitialSpeed:     20,
+		HalfLife:         5 * time.Minute,
+		PredictionStep:   30 * time.Second,
+		PredictionLength: 5 * time.Minute,
+	}
+}
+
+type vector [4]float64
+type matrix [4][4]float64
+
+type state struct {
+	x    vector // east, north, east speed, north speed
+	p    matrix // covariance
+	time time.Time
+}
+
+// Track runs the filter over the lines and returns the track as of now
+func (t *Tracker) Track(lines []*types.Line, now time.Time) (*types.Track, error) {
+	if len(lines) == 0 {
+		return nil, errors.New("no lines to track")
+	}
+	sorted := make([]*types.Line, len(lines))
+	copy(sorted, lines)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	initial, err := estimator.Estimate(sorted)
+	if err != nil {
+		return nil, err
+	}
+	originLongitude, originLatitude := initial.Longitude, initial.Latitude
+
+	positionVariance := math.Max(initial.Radius, 1000)
+	positionVariance *= positionVariance
+	s := state{time: sorted[0].Timestamp}
+	s.p[0][0], s.p[1][1] = positionVariance, positionVariance
+	s.p[2][2], s.p[3][3] = t.InitialSpeed*t.InitialSpeed, t.InitialSpeed*t.InitialSpeed
+
+	track := types.Track{}
+	for i, line := range sorted {
+		t.predict(&s, line.Timestamp)
+		x, y := estimator.Project(originLongitude, originLatitude, line.Longitude, line.Latitude)
+		t.update(&s, x, y, float64(line.Bearing), t.weight(now.Sub(line.Timestamp)))
+		track.History = append(track.History, t.estimate(s, originLongitude, originLatitude, i+1))
+	}
+
+	if now.After(s.time) {
+		t.predict(&s, now)
+	}
+	track.Estimate = *t.estimate(s, originLongitude, originLatitude, len(lines))
+	track.Speed = math.Hypot(s.x[2], s.x[3])
+	track.Course = math.Mod(math.Atan2(s.x[2], s.x[3])*180/math.Pi+360, 360)
+
+	for ahead := time.Duration(0); ahead <= t.PredictionLength && t.PredictionStep > 0; ahead += t.PredictionStep {
+		future := s
+		t.predict(&future, now.Add(ahead))
+		track.Prediction = append(track.Prediction, t.estimate(future, originLongitude, originLatitude, len(lines)))
+	}
+	return &track, nil
+}
+
+// weight returns the weight of a bearing of the given age, between 0 and 1
+func (t *Tracker) weight(age time.Duration) float64 {
+	if t.HalfLife <= 0 || age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, age.Seconds()/t.HalfLife.Seconds())
+}
+
+// predict moves the state forward in time with the constant velocity model
+func (t *Tracker) predict(s *state, to time.Time) {
+	dt := to.Sub(s.time).Seconds()
+	s.time = to
+	if dt <= 0 {
+		return
+	}
+
+	s.x[0] += s.x[2] * dt
+	s.x[1] += s.x[3] * dt
+
+	f := identity()
+	f[0][2], f[1][3] = dt, dt
+	s.p = multiply(multiply(f, s.p), transpose(f))
+
+	q := t.Acceleration * t.Acceleration
+	for axis := 0; axis < 2; axis++ {
+		s.p[axis][axis] += q * dt * dt * dt * dt / 4
+		s.p[axis][axis+2] += q * dt * dt * dt / 2
+		s.p[axis+2][axis] += q * dt * dt * dt / 2
+		s.p[axis+2][axis+2] += q * dt * dt
+	}
+}
+
+// update corrects the state with a bearing taken from the station at (x, y)
+func (t *Tracker) update(s *state, x, y, bearing, weight float64) {
+	dx, dy := s.x[0]-x, s.x[1]-y
+	r2 := dx*dx + dy*dy
+	if r2 < 1 || weight <= 0 {
+		return
+	}
+
+	h := vector{dy / r2, -dx / r2, 0, 0}
+	predicted := math.Atan2(dx, dy)
+	innovation := math.Remainder(bearing*math.Pi/180-predicted, 2*math.Pi)
+
+	sigma := t.BearingError * math.Pi / 180
+	var ph vector
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			ph[i] += s.p[i][j] * h[j]
+		}
+	}
+	variance := sigma * sigma / weight
+	for i := 0; i < 4; i++ {
+		variance += h[i] * ph[i]
+	}
+
+	for i := 0; i < 4; i++ {
+		s.x[i] += ph[i] / variance * innovation
+	}
+	var updated matrix
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			updated[i][j] = s.p[i][j] - ph[i]*ph[j]/variance
+		}
+	}
+	s.p = updated
+}
+
+func (t *Tracker) estimate(s state, originLongitude, originLatitude float64, lines int) *types.Estimate {
+	longitude, latitude := estimator.Unproject(originLongitude, originLatitude, s.x[0], s.x[1])
+	return &types.Estimate{
+		Timestamp: s.time,
+		Longitude: longitude,
+		Latitude:  latitude,
+		Radius:    math.Sqrt(s.p[0][0] + s.p[1][1]),
+		Lines:     lines,
+	}
+}
+
+func identity() (m matrix) {
+	for i := 0; i < 4; i++ {
+		m[i][i] = 1
+	}
+	return
+}
+
+func multiply(a, b matrix) (m matrix) {
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				m[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return
+}
+
+func transpose(a matrix) (m matrix) {
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			m[i][j] = a[j][i]
+		}
+	}
+	return
+}
@@ -0,0 +1,85 @@
+package tracker
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/kellydunn/golang-geo"
+	"math"
+	"testing"
+	"time"
+)
+
+// movingLines simulates a transmitter going east at 10 m/s, seen by three fixed stations every 10 seconds
+func movingLines(start time.Time, duration time.Duration) []*types.Line {
+	stations := []types.Position{
+		{Station: "a", Longitude: 4.95, Latitude: 51.97},
+		{Station: "b", Longitude: 5.1, Latitude: 51.96},
+		{Station: "c", Longitude: 5.05, Latitude: 52.05},
+	}
+	var lines []*types.Line
+	for offset := time.Duration(0); offset <= duration; offset += 10 * time.Second {
+		longitude, latitude := estimator.Unproject(5, 52, 10*offset.Seconds(), 0)
+		transmitter := geo.NewPoint(latitude, longitude)
+		for _, station := range stations {
+			bearing := geo.NewPoint(station.Latitude, station.Longitude).BearingTo(transmitter)
+			position := station
+			position.Timestamp = start.Add(offset)
+			lines = append(lines, &types.Line{Position: position, Bearing: int(math.Round(math.Mod(bearing+360, 360)))})
+		}
+	}
+	return lines
+}
+
+func TestTracker_Track(t *testing.T) {
+	start := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	duration := 10 * time.Minute
+	track, err := New().Track(movingLines(start, duration), start.Add(duration))
+	if err != nil {
+		t.Fatalf("Track() returned error: %e", err)
+	}
+
+	longitude, latitude := estimator.Unproject(5, 52, 10*duration.Seconds(), 0)
+	distance := geo.NewPoint(latitude, longitude).GreatCircleDistance(geo.NewPoint(track.Latitude, track.Longitude)) * 1000
+	if distance > 100 {
+		t.Errorf("track is %f metres off: %+v", distance, track.Estimate)
+	}
+	if math.Abs(track.Speed-10) > 2 {
+		t.Errorf("expected a speed of 10 m/s, got %f", track.Speed)
+	}
+	if math.Abs(track.Course-90) > 10 {
+		t.Errorf("expected a course of 90 degrees, got %f", track.Course)
+	}
+	if len(track.History) != 183 {
+		t.Errorf("expected 183 history items, got %d", len(track.History))
+	}
+	if len(track.Prediction) != 11 {
+		t.Fatalf("expected 11 predicted positions, got %d", len(track.Prediction))
+	}
+	if track.Prediction[10].Longitude <= track.Longitude || track.Prediction[10].Radius <= track.Radius {
+		t.Errorf("expected the prediction to move east with growing uncertainty: %+v", track.Prediction[10])
+	}
+}
+
+func TestTracker_Track_NoLines(t *testing.T) {
+	if _, err := New().Track(nil, time.Now()); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestTracker_weight(t *testing.T) {
+	tracker := New()
+	tests := []struct {
+		age  time.Duration
+		want float64
+	}{
+		{0, 1},
+		{-time.Minute, 1},
+		{tracker.HalfLife, 0.5},
+		{2 * tracker.HalfLife, 0.25},
+	}
+	for _, tt := range tests {
+		if got := tracker.weight(tt.age); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("weight(%s) = %f, want %f", tt.age, got, tt.want)
+		}
+	}
+}
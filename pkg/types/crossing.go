@@ -1,7 +1,15 @@
 package types
 
+import "time"
+
 type Crossing struct {
+	Timestamp time.Time // of the newest line, or of the newest crossing in a cluster
 	Longitude float64
 	Latitude  float64
 	Weight    int
+	Stations  []string           // stations that contributed lines
+	Spread    float64            // RMS distance of the clustered crossings to the centre, in metres
+	Angle     float64            // angle between the crossing lines, 0 - 90 degrees
+	Distances map[string]float64 // distance from each station, in metres
+	Dilution  float64            // position error in metres per degree of bearing error
 }
@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+// Estimate is a calculated transmitter position
+type Estimate struct {
+	Timestamp time.Time
+	Longitude float64
+	Latitude  float64
+	Radius    float64 // uncertainty in metres
+	Lines     int     // amount of bearing lines used
+}
@@ -4,4 +4,10 @@ type Line struct {
 	Position
 	LongitudeEnd float64
 	LatitudeEnd  float64
+	Bearing      int
+	Suspect      bool
+	Spread       float64
+	Quality      float64
+	Frequency    float64
+	Vector       []float64
 }
@@ -10,4 +10,9 @@ type Measurement struct {
 	Longitude float64
 	Latitude  float64
 	Bearing   int
+	Suspect   bool      // flagged as a likely reflection or other outlier
+	Spread    float64   // circular standard deviation of a smoothed bearing, in degrees
+	Quality   float64   // signal quality as reported by the DF unit
+	Frequency float64   // in MHz
+	Vector    []float64 // direction of arrival spectrum from 0 - 359 degrees, as reported by the DF unit
 }
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// Status is what a receiver reports about a station at a regular interval, to tell whether the station is alive
+type Status struct {
+	Timestamp time.Time
+	Station   string
+	LastSeen  time.Time // of the last measurement, zero when there wasn't any
+	Messages  int       // measurements received since the last report
+	Errors    int       // measurements that couldn't be parsed or stored since the last report
+	Bearing   int       // of the last measurement
+	FixAge    float64   // seconds since the last GPS fix of the receiver, -1 when unknown
+}
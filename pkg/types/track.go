@@ -0,0 +1,10 @@
+package types
+
+// Track is the filtered state of a moving transmitter
+type Track struct {
+	Estimate               // current position
+	Speed      float64     // metres per second
+	Course     float64     // degrees
+	History    []*Estimate // filtered position after each bearing
+	Prediction []*Estimate // predicted future positions
+}
@@ -0,0 +1,17 @@
+package web
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/alerts"
+)
+
+// SetAlerts runs the alert rules over the database of the server while serving, and pushes the alerts as alert events
+func (s *server) SetAlerts(engine *alerts.Engine) {
+	if engine.Database == nil {
+		engine.Database = s.db
+	}
+	engine.Sinks = append(engine.Sinks, alerts.SinkFunc(func(alert *alerts.Alert) error {
+		s.Publish("alert", alert)
+		return nil
+	}))
+	s.alerts = engine
+}
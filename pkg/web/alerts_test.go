@@ -0,0 +1,39 @@
+package web
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/alerts"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/matryer/is"
+	"testing"
+	"time"
+)
+
+func TestSetAlerts(t *testing.T) {
+	Is := is.New(t)
+	now := time.Now()
+	db := &databasetest.Database{
+		Lines: []*types.Line{
+			{Position: types.Position{Timestamp: now, Station: "west", Longitude: 5, Latitude: 52.05}, Bearing: 90},
+			{Position: types.Position{Timestamp: now, Station: "south", Longitude: 5.05, Latitude: 52}, Bearing: 0},
+		},
+		Positions: []*types.Position{{Timestamp: now, Station: "south", Longitude: 5.05, Latitude: 52.049}},
+	}
+	srv := &server{db: db}
+	events := srv.events.subscribe()
+	defer srv.events.unsubscribe(events)
+
+	engine := alerts.NewEngine(nil, &alerts.Proximity{Distance: 200})
+	srv.SetAlerts(engine)
+	Is.Equal(engine.Database, db)
+	_, err := engine.Check()
+	Is.NoErr(err)
+
+	select {
+	case e := <-events:
+		Is.Equal(e.name, "alert")
+		Is.Equal(e.data.(*alerts.Alert).Station, "south")
+	case <-time.After(time.Second):
+		t.Fatal("no alert event")
+	}
+}
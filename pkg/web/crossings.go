@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/cluster"
+	"github.com/hsmade/OSM-ARDF/pkg/quality"
 	"github.com/hsmade/OSM-ARDF/pkg/types"
 	"github.com/paulmach/go.geojson"
 	"log"
@@ -24,18 +26,63 @@ func (s *server) handleCrossings() gin.HandlerFunc {
 			_ = c.AbortWithError(500, errors.New(fmt.Sprintf("unable to get crossings: %e", err)))
 			return
 		}
+		thresholds, distance, err := s.crossingFilters(c)
+		if err != nil {
+			_ = c.AbortWithError(500, err)
+			return
+		}
 		log.Printf("got %d crossings", len(crossings))
+		crossings = s.clusterCrossings(crossings, thresholds, distance)
 		c.String(200, string(formatCrossings(crossings)))
 	}
 }
 
+// crossingFilters reads the distance, min_angle and max_dilution query parameters, with the server settings as defaults
+func (s *server) crossingFilters(c *gin.Context) (thresholds quality.Thresholds, distance float64, err error) {
+	distance = s.clusterDistance
+	if value := c.Query("distance"); value != "" {
+		distance, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return thresholds, 0, errors.New("distance must be a number")
+		}
+	}
+	thresholds = *s.thresholds
+	if value := c.Query("min_angle"); value != "" {
+		thresholds.MinAngle, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return thresholds, 0, errors.New("min_angle must be a number")
+		}
+	}
+	if value := c.Query("max_dilution"); value != "" {
+		thresholds.MaxDilution, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return thresholds, 0, errors.New("max_dilution must be a number")
+		}
+	}
+	return thresholds, distance, nil
+}
+
+// clusterCrossings drops the crossings with poor geometry and clusters the rest
+func (s *server) clusterCrossings(crossings []*types.Crossing, thresholds quality.Thresholds, distance float64) []*types.Crossing {
+	crossings = thresholds.Filter(crossings)
+	log.Printf("kept %d crossings with good geometry", len(crossings))
+	crossings = cluster.Crossings(crossings, distance, s.clusterMinPoints)
+	log.Printf("clustered into %d crossings", len(crossings))
+	return crossings
+}
+
 func formatCrossings(crossings []*types.Crossing) []byte {
 	fc := geojson.NewFeatureCollection()
 	for _, crossing := range crossings {
 		pointFeature := geojson.NewPointFeature([]float64{crossing.Longitude, crossing.Latitude})
 		pointFeature.Properties = map[string]interface{}{
-			"id": fmt.Sprintf("%f %f %d",crossing.Longitude, crossing.Latitude, crossing.Weight),
-			"weight": crossing.Weight,
+			"id":        fmt.Sprintf("%f %f %d", crossing.Longitude, crossing.Latitude, crossing.Weight),
+			"weight":    crossing.Weight,
+			"stations":  crossing.Stations,
+			"spread":    crossing.Spread,
+			"angle":     crossing.Angle,
+			"distances": crossing.Distances,
+			"dilution":  crossing.Dilution,
 		}
 		fc.AddFeature(pointFeature)
 	}
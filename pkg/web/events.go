@@ -0,0 +1,74 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"sync"
+)
+
+// event is a message on the push channel
+type event struct {
+	name string
+	data interface{}
+}
+
+// hub passes events to the clients of the push channel, and drops them for clients that can't keep up
+type hub struct {
+	mutex   sync.Mutex
+	clients map[chan event]bool
+}
+
+func (h *hub) subscribe() chan event {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.clients == nil {
+		h.clients = map[chan event]bool{}
+	}
+	events := make(chan event, 16)
+	h.clients[events] = true
+	return events
+}
+
+func (h *hub) unsubscribe(events chan event) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.clients, events)
+}
+
+func (h *hub) publish(e event) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for events := range h.clients {
+		select {
+		case events <- e:
+		default:
+		}
+	}
+}
+
+// Publish sends an event with the data as JSON to the clients of the push channel
+func (s *server) Publish(name string, data interface{}) {
+	s.events.publish(event{name: name, data: data})
+}
+
+// handleEvents is the push channel, which streams the events as server-sent events
+func (s *server) handleEvents() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		events := s.events.subscribe()
+		defer s.events.unsubscribe(events)
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		// send the headers right away, so clients know they're connected before the first event
+		c.Status(200)
+		c.Writer.Flush()
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case e := <-events:
+				c.SSEvent(e.name, e.data)
+				return true
+			case <-c.Request.Context().Done():
+				return false
+			}
+		})
+	}
+}
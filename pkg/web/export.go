@@ -0,0 +1,105 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/estimator"
+	"github.com/hsmade/OSM-ARDF/pkg/export"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"log"
+	"strconv"
+	"time"
+)
+
+func (s *server) handleExportKML() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hunt, err := s.hunt(c)
+		if err != nil {
+			_ = c.AbortWithError(500, err)
+			return
+		}
+		data, err := hunt.KML()
+		if err != nil {
+			_ = c.AbortWithError(500, errors.New(fmt.Sprintf("unable to create kml: %e", err)))
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename=hunt.kml")
+		c.Data(200, "application/vnd.google-earth.kml+xml", data)
+	}
+}
+
+func (s *server) handleExportKMZ() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hunt, err := s.hunt(c)
+		if err != nil {
+			_ = c.AbortWithError(500, err)
+			return
+		}
+		data, err := hunt.KMZ()
+		if err != nil {
+			_ = c.AbortWithError(500, errors.New(fmt.Sprintf("unable to create kmz: %e", err)))
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename=hunt.kmz")
+		c.Data(200, "application/vnd.google-earth.kmz", data)
+	}
+}
+
+func (s *server) handleExportGPX() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hunt, err := s.hunt(c)
+		if err != nil {
+			_ = c.AbortWithError(500, err)
+			return
+		}
+		data, err := hunt.GPX()
+		if err != nil {
+			_ = c.AbortWithError(500, errors.New(fmt.Sprintf("unable to create gpx: %e", err)))
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename=hunt.gpx")
+		c.Data(200, "application/gpx+xml", data)
+	}
+}
+
+// hunt collects the positions, headings, crossings and estimate of the last seconds.
+// The crossings are filtered like the crossings API does.
+func (s *server) hunt(c *gin.Context) (*export.Hunt, error) {
+	seconds := c.Query("seconds")
+	since, err := strconv.Atoi(seconds)
+	if err != nil {
+		return nil, errors.New("seconds must be a number")
+	}
+	thresholds, distance, err := s.crossingFilters(c)
+	if err != nil {
+		return nil, err
+	}
+	hunt := export.Hunt{Name: fmt.Sprintf("OSM-ARDF hunt %s", time.Now().Format(time.RFC3339))}
+
+	hunt.Positions, err = s.db.GetPositions(time.Duration(since) * time.Second)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("unable to get positions: %e", err))
+	}
+	hunt.Lines, err = s.db.GetLines(time.Duration(since) * time.Second)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("unable to get lines: %e", err))
+	}
+	crossings, err := s.db.GetCrossings(time.Duration(since) * time.Second)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("unable to get crossings: %e", err))
+	}
+	hunt.Crossings = s.clusterCrossings(crossings, thresholds, distance)
+
+	var lines []*types.Line
+	for _, line := range hunt.Lines {
+		if !line.Suspect {
+			lines = append(lines, line)
+		}
+	}
+	if estimate, err := estimator.Estimate(lines); err == nil {
+		hunt.Estimates = []*types.Estimate{estimate}
+	}
+	log.Printf("exporting %d positions, %d lines and %d crossings", len(hunt.Positions), len(hunt.Lines), len(hunt.Crossings))
+	return &hunt, nil
+}
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/quality"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/matryer/is"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// exportedKML is the part of the exported KML that the tests look at
+type exportedKML struct {
+	Folders []struct {
+		Name       string `xml:"name"`
+		Placemarks []struct {
+			Name string `xml:"name"`
+			When string `xml:"TimeStamp>when"`
+		} `xml:"Placemark"`
+	} `xml:"Document>Folder"`
+}
+
+func TestExport(t *testing.T) {
+	Is := is.New(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	db := &databasetest.Database{
+		Positions: []*types.Position{{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99}},
+		Lines: []*types.Line{
+			{Position: types.Position{Timestamp: now.Add(-time.Second), Station: "south", Longitude: 5, Latitude: 51.99}, LongitudeEnd: 5, LatitudeEnd: 52.2},
+			{Position: types.Position{Timestamp: now, Station: "west", Longitude: 4.99, Latitude: 52}, LongitudeEnd: 5.2, LatitudeEnd: 52, Bearing: 90},
+		},
+		Crossings: []*types.Crossing{{Timestamp: now, Longitude: 5, Latitude: 52, Weight: 1, Stations: []string{"south", "west"}, Angle: 90, Dilution: 1}},
+	}
+	srv := &server{
+		router:     gin.Default(),
+		db:         db,
+		thresholds: quality.New(),
+	}
+	srv.routes()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/api/export.kml?seconds=60")
+	Is.Equal(w.Code, http.StatusOK)
+	Is.Equal(w.Header().Get("Content-Type"), "application/vnd.google-earth.kml+xml")
+	var document exportedKML
+	Is.NoErr(xml.Unmarshal(w.Body.Bytes(), &document))
+	folders := map[string]int{}
+	for _, folder := range document.Folders {
+		folders[folder.Name] = len(folder.Placemarks)
+		// everything replays on the time slider, except the tracks that have a time span
+		for _, placemark := range folder.Placemarks {
+			if folder.Name != "Tracks" {
+				Is.True(placemark.When != "")
+			}
+		}
+	}
+	Is.Equal(folders["Bearings"], 2)
+	Is.Equal(folders["Crossings"], 1)
+	Is.Equal(folders["Estimates"], 1)
+
+	w = get("/api/export.kmz?seconds=60")
+	Is.Equal(w.Code, http.StatusOK)
+	Is.Equal(w.Header().Get("Content-Type"), "application/vnd.google-earth.kmz")
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	Is.NoErr(err)
+	Is.Equal(len(archive.File), 1)
+	file, err := archive.File[0].Open()
+	Is.NoErr(err)
+	data, err := ioutil.ReadAll(file)
+	Is.NoErr(err)
+	Is.NoErr(xml.Unmarshal(data, &document))
+
+	Is.Equal(get("/api/export.kml").Code, http.StatusInternalServerError)
+}
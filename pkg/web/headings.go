@@ -33,7 +33,13 @@ func formatHeadings(lines []*types.Line) []byte {
 	fc := geojson.NewFeatureCollection()
 	for _, line := range lines {
 		pointFeature := geojson.NewLineStringFeature([][]float64{{line.Longitude, line.Latitude}, {line.LongitudeEnd, line.LatitudeEnd}})
-		pointFeature.Properties = map[string]interface{}{"id": line.Station + line.Timestamp.String()}
+		pointFeature.Properties = map[string]interface{}{
+			"id":      line.Station + line.Timestamp.String(),
+			"station": line.Station,
+			"bearing": line.Bearing,
+			"suspect": line.Suspect,
+			"spread":  line.Spread,
+		}
 		fc.AddFeature(pointFeature)
 	}
 	rawJSON, err := fc.MarshalJSON()
@@ -0,0 +1,108 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+)
+
+const maxIngestSize = 1 << 20
+
+// ingestError is the result of a measurement in a batch that could not be stored
+type ingestError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// handleIngest stores a single measurement, or a batch of measurements as a JSON array.
+// The station defaults to the station of the token, and may not be another station.
+func (s *server) handleIngest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		station := c.GetString("station")
+		body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIngestSize))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+
+		body = bytes.TrimSpace(body)
+		if !bytes.HasPrefix(body, []byte("[")) {
+			m := &types.Measurement{}
+			if err := json.Unmarshal(body, m); err != nil {
+				s.recordError(station)
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid measurement: %v", err)})
+				return
+			}
+			if status, err := s.ingest(station, m); err != nil {
+				c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusCreated, m)
+			return
+		}
+
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid batch: %v", err)})
+			return
+		}
+		errs := []ingestError{}
+		for i, item := range batch {
+			m := &types.Measurement{}
+			if err := json.Unmarshal(item, m); err != nil {
+				s.recordError(station)
+				errs = append(errs, ingestError{i, fmt.Sprintf("invalid measurement: %v", err)})
+				continue
+			}
+			if _, err := s.ingest(station, m); err != nil {
+				errs = append(errs, ingestError{i, err.Error()})
+			}
+		}
+		log.Printf("stored %d of %d measurements of %s", len(batch)-len(errs), len(batch), station)
+
+		status := http.StatusCreated
+		if len(errs) > 0 {
+			status = http.StatusMultiStatus
+		}
+		c.JSON(status, gin.H{"stored": len(batch) - len(errs), "errors": errs})
+	}
+}
+
+// ingest validates and stores a measurement, and returns the HTTP status for the error
+func (s *server) ingest(station string, m *types.Measurement) (int, error) {
+	if m.Station == "" {
+		m.Station = station
+	}
+	if m.Station != station {
+		s.recordError(station)
+		return http.StatusForbidden, fmt.Errorf("token is not valid for station %s", m.Station)
+	}
+	if m.Timestamp.IsZero() {
+		m.Timestamp = time.Now()
+	}
+	if err := database.Validate(m); err != nil {
+		s.recordError(station)
+		return http.StatusBadRequest, err
+	}
+	if err := s.db.Add(m); err != nil {
+		s.recordError(station)
+		return http.StatusInternalServerError, fmt.Errorf("unable to store measurement: %v", err)
+	}
+	if s.recorder != nil {
+		s.recorder.Measurement(m)
+	}
+	return http.StatusCreated, nil
+}
+
+func (s *server) recordError(station string) {
+	if s.recorder != nil {
+		s.recorder.Error(station)
+	}
+}
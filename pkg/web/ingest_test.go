@@ -0,0 +1,109 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/matryer/is"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func ingestServer() (*server, *databasetest.Database) {
+	db := &databasetest.Database{Reject: func(m *types.Measurement) error {
+		if m.Frequency < 0 {
+			return errors.New("no frequency")
+		}
+		return nil
+	}}
+	srv := &server{
+		router: gin.Default(),
+		db:     db,
+		tokens: Tokens{"fox1": "secret1", "fox2": "secret2"},
+	}
+	srv.routes()
+	return srv, db
+}
+
+func post(srv *server, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/measurements", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIngest_Authentication(t *testing.T) {
+	Is := is.New(t)
+	srv, db := ingestServer()
+
+	Is.Equal(post(srv, "", `{"Bearing": 10}`).Code, http.StatusUnauthorized)
+	Is.Equal(post(srv, "wrong", `{"Bearing": 10}`).Code, http.StatusUnauthorized)
+	Is.Equal(post(srv, "secret1", `{"Station": "fox2", "Bearing": 10}`).Code, http.StatusForbidden)
+	Is.Equal(len(db.Measurements()), 0)
+}
+
+func TestIngest_Single(t *testing.T) {
+	Is := is.New(t)
+	srv, db := ingestServer()
+
+	w := post(srv, "secret2", `{"Timestamp": "2019-10-01T12:00:00Z", "Longitude": 5, "Latitude": 52, "Bearing": 90}`)
+	Is.Equal(w.Code, http.StatusCreated)
+	Is.Equal(len(db.Measurements()), 1)
+	Is.Equal(db.Measurements()[0].Station, "fox2")
+	Is.Equal(db.Measurements()[0].Bearing, 90)
+
+	Is.Equal(post(srv, "secret2", `{"Bearing": 400}`).Code, http.StatusBadRequest)
+	Is.Equal(post(srv, "secret2", `{"Bearing": "north"}`).Code, http.StatusBadRequest)
+	Is.Equal(post(srv, "secret2", `{"Bearing": 10, "Frequency": -1}`).Code, http.StatusInternalServerError)
+	Is.Equal(len(db.Measurements()), 1)
+}
+
+func TestIngest_Batch(t *testing.T) {
+	Is := is.New(t)
+	srv, db := ingestServer()
+
+	w := post(srv, "secret1", `[{"Bearing": 10}, {"Bearing": -1}, {"Station": "fox2", "Bearing": 10}, "x", {"Bearing": 20}]`)
+	Is.Equal(w.Code, http.StatusMultiStatus)
+	var result struct {
+		Stored int
+		Errors []ingestError
+	}
+	Is.NoErr(json.Unmarshal(w.Body.Bytes(), &result))
+	Is.Equal(result.Stored, 2)
+	Is.Equal(len(result.Errors), 3)
+	Is.Equal(result.Errors[0].Index, 1)
+	Is.Equal(result.Errors[1].Index, 2)
+	Is.Equal(result.Errors[2].Index, 3)
+	Is.Equal(len(db.Measurements()), 2)
+	Is.True(!db.Measurements()[0].Timestamp.IsZero())
+
+	Is.Equal(post(srv, "secret1", `[{"Bearing": 30}]`).Code, http.StatusCreated)
+	Is.Equal(post(srv, "secret1", `[{"Bearing": 30}`).Code, http.StatusBadRequest)
+}
+
+func TestLoadTokens(t *testing.T) {
+	Is := is.New(t)
+	file, err := ioutil.TempFile("", "tokens")
+	Is.NoErr(err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("# station token\nfox1 secret1\n\n  fox2\tsecret2\n")
+	Is.NoErr(err)
+	Is.NoErr(file.Close())
+
+	tokens, err := LoadTokens(file.Name())
+	Is.NoErr(err)
+	Is.Equal(tokens, Tokens{"fox1": "secret1", "fox2": "secret2"})
+
+	Is.NoErr(ioutil.WriteFile(file.Name(), []byte("fox1\n"), 0644))
+	_, err = LoadTokens(file.Name())
+	Is.True(err != nil)
+}
@@ -0,0 +1,53 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/tiles"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// SetMap serves the tiles of a source as the base map with the given name
+func (s *server) SetMap(name string, source tiles.Source) {
+	if s.maps == nil {
+		s.maps = map[string]tiles.Source{}
+	}
+	s.maps[name] = source
+}
+
+// handleMap serves a tile of a base map at /tiles/{name}/{z}/{x}/{y}.{format}
+func (s *server) handleMap() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		source, found := s.maps[c.Param("name")]
+		if !found {
+			_ = c.AbortWithError(404, errors.New(fmt.Sprintf("unknown map %s", c.Param("name"))))
+			return
+		}
+		z, errZ := strconv.ParseUint(c.Param("z"), 10, 8)
+		x, errX := strconv.ParseUint(c.Param("x"), 10, 32)
+		y, errY := strconv.ParseUint(strings.TrimSuffix(c.Param("y"), path.Ext(c.Param("y"))), 10, 32)
+		if errZ != nil || errX != nil || errY != nil {
+			_ = c.AbortWithError(400, errors.New("z, x and y must be numbers"))
+			return
+		}
+		if !tiles.Valid(uint8(z), uint32(x), uint32(y)) {
+			_ = c.AbortWithError(400, errors.New(fmt.Sprintf("invalid tile %d/%d/%d", z, x, y)))
+			return
+		}
+
+		data, format, err := source.Tile(uint8(z), uint32(x), uint32(y))
+		if err == tiles.ErrNotFound {
+			_ = c.AbortWithError(404, err)
+			return
+		}
+		if err != nil {
+			_ = c.AbortWithError(502, err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=86400")
+		c.Data(200, tiles.ContentType(format), data)
+	}
+}
@@ -0,0 +1,68 @@
+package web
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/tiles"
+	"github.com/matryer/is"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMaps(t *testing.T) {
+	Is := is.New(t)
+	// a stand-in for the tile server, with tiles up to zoom 14
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var z, x, y int
+		if _, err := fmt.Sscanf(r.URL.Path, "/%d/%d/%d.png", &z, &x, &y); err != nil || z > 14 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "%d/%d/%d", z, x, y)
+	}))
+	defer upstream.Close()
+
+	srv := &server{router: gin.Default()}
+	srv.routes()
+	srv.SetMap("osm", tiles.NewUpstream(upstream.URL+"/{z}/{x}/{y}.png", ""))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/tiles/osm/12/2104/1350.png")
+	Is.Equal(w.Code, http.StatusOK)
+	Is.Equal(w.Header().Get("Content-Type"), "image/png")
+	Is.Equal(w.Body.String(), "12/2104/1350")
+
+	// a chain serves each tile with the content type of the source that has it
+	directory, err := ioutil.TempDir("", "maps")
+	Is.NoErr(err)
+	defer os.RemoveAll(directory)
+	local, err := tiles.CreateMBTiles(filepath.Join(directory, "aerial.mbtiles"))
+	Is.NoErr(err)
+	defer local.Close()
+	Is.NoErr(local.SetMetadata("format", "jpg"))
+	Is.NoErr(local.Put(12, 2104, 1350, []byte("local")))
+	srv.SetMap("mixed", tiles.Chain{local, tiles.NewUpstream(upstream.URL+"/{z}/{x}/{y}.png", "")})
+	w = get("/tiles/mixed/12/2104/1350.jpg")
+	Is.Equal(w.Code, http.StatusOK)
+	Is.Equal(w.Header().Get("Content-Type"), "image/jpeg")
+	w = get("/tiles/mixed/12/2104/1351.png")
+	Is.Equal(w.Code, http.StatusOK)
+	Is.Equal(w.Header().Get("Content-Type"), "image/png")
+
+	Is.Equal(get("/tiles/osm/15/0/0.png").Code, http.StatusNotFound)
+	Is.Equal(get("/tiles/topo/12/2104/1350.png").Code, http.StatusNotFound)
+	Is.Equal(get("/tiles/osm/12/x/1350.png").Code, http.StatusBadRequest)
+	Is.Equal(get("/tiles/osm/2/4/0.png").Code, http.StatusBadRequest)
+
+	upstream.Close()
+	Is.Equal(get("/tiles/osm/12/2104/1351.png").Code, http.StatusBadGateway)
+}
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/export"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const maxImportSize = 32 << 20
+
+func (s *server) handleMeasurementsCSV() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seconds := c.Query("seconds")
+		since, err := strconv.Atoi(seconds)
+		if err != nil {
+			_ = c.AbortWithError(500, errors.New("seconds must be a number"))
+			return
+		}
+		format, err := csvFormat(c)
+		if err != nil {
+			_ = c.AbortWithError(500, err)
+			return
+		}
+
+		lines, err := s.db.GetLines(time.Duration(since) * time.Second)
+		if err != nil {
+			_ = c.AbortWithError(500, errors.New(fmt.Sprintf("unable to get lines: %e", err)))
+			return
+		}
+		log.Printf("exporting %d measurements", len(lines))
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment; filename=measurements.csv")
+		if err := format.Write(c.Writer, lines); err != nil {
+			log.Printf("error writing csv: %e", err)
+		}
+	}
+}
+
+// handleImportCSV stores the measurements in the posted CSV, like the csv command imports a file.
+// Every row is ingested like a measurement in a batch, and the errors are reported by row number,
+// counting the header as row 1.
+func (s *server) handleImportCSV() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		station := c.GetString("station")
+		format, err := csvFormat(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rows := 0
+		errs := []ingestError{}
+		err = format.Scan(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize), func(row int, m *types.Measurement, err error) {
+			rows++
+			if err != nil {
+				s.recordError(station)
+				errs = append(errs, ingestError{row, fmt.Sprintf("invalid measurement: %v", err)})
+				return
+			}
+			if _, err := s.ingest(station, m); err != nil {
+				errs = append(errs, ingestError{row, err.Error()})
+			}
+		})
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid csv: %v", err)})
+			return
+		}
+		log.Printf("imported %d of %d measurements of %s", rows-len(errs), rows, station)
+
+		status := http.StatusCreated
+		if len(errs) > 0 {
+			status = http.StatusMultiStatus
+		}
+		c.JSON(status, gin.H{"stored": rows - len(errs), "errors": errs})
+	}
+}
+
+// csvFormat returns the CSV format of the timezone, time_format and columns query parameters
+func csvFormat(c *gin.Context) (*export.CSV, error) {
+	location, err := time.LoadLocation(c.Query("timezone"))
+	if err != nil || c.Query("timezone") == "" {
+		return nil, errors.New("timezone must be a time zone like UTC or Europe/Amsterdam")
+	}
+	format := export.NewCSV(location)
+	if value := c.Query("time_format"); value != "" {
+		format.TimeFormat = value
+	}
+	if err := format.MapColumns(c.Query("columns")); err != nil {
+		return nil, err
+	}
+	return format, nil
+}
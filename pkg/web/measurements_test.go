@@ -0,0 +1,48 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/matryer/is"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestImportCSV(t *testing.T) {
+	Is := is.New(t)
+	srv, db := ingestServer()
+
+	postCSV := func(query, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/measurements.csv"+query, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret1")
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	input := "Date,Callsign,DoA\n" +
+		"2019-10-01T12:00:00Z,fox1,10\n" +
+		"2019-10-01T12:00:01Z,fox1,north\n" +
+		"2019-10-01T12:00:02Z,fox1,400\n" +
+		"2019-10-01T12:00:03Z,fox2,10\n" +
+		"2019-10-01T12:00:04Z,fox1,20\n"
+	w := postCSV("?timezone=UTC&columns=time=Date,station=Callsign,bearing=DoA", input)
+	Is.Equal(w.Code, http.StatusMultiStatus)
+	var result struct {
+		Stored int
+		Errors []ingestError
+	}
+	Is.NoErr(json.Unmarshal(w.Body.Bytes(), &result))
+	Is.Equal(result.Stored, 2)
+	Is.Equal(len(result.Errors), 3)
+	Is.Equal(result.Errors[0].Index, 3) // bearing is not a number
+	Is.Equal(result.Errors[1].Index, 4) // bearing out of range
+	Is.Equal(result.Errors[2].Index, 5) // another station
+	Is.Equal(len(db.Measurements()), 2)
+	Is.Equal(db.Measurements()[1].Bearing, 20)
+
+	Is.Equal(postCSV("?timezone=UTC", "time,station,bearing\n2019-10-01T12:00:00Z,fox1,30\n").Code, http.StatusCreated)
+	Is.Equal(postCSV("?timezone=UTC", "time,station\n").Code, http.StatusBadRequest)
+	Is.Equal(postCSV("", "time,station,bearing\n").Code, http.StatusBadRequest)
+}
@@ -10,10 +10,23 @@ func (s *server) routes() {
 	log.Info("loading routes")
 	s.router.StaticFS("/app", web.Assets)
 	s.router.GET("/", s.redirectRoot)
+	s.router.GET("/tiles/:name/:z/:x/:y", s.handleMap())
 	api := s.router.Group("api")
 	api.GET("/positions", s.handlePostions())
+	api.GET("/positions/latest", s.handleLatestPositions())
+	api.GET("/tracks", s.handleTracks())
 	api.GET("/headings", s.handleHeadings())
 	api.GET("/crossings", s.handleCrossings())
+	api.GET("/track", s.handleTrack())
+	api.GET("/export.kml", s.handleExportKML())
+	api.GET("/export.kmz", s.handleExportKMZ())
+	api.GET("/export.gpx", s.handleExportGPX())
+	api.GET("/measurements.csv", s.handleMeasurementsCSV())
+	api.POST("/measurements.csv", s.authenticate(), s.handleImportCSV())
+	api.GET("/tiles/:layer/:z/:x/:y", s.handleTiles())
+	api.POST("/measurements", s.authenticate(), s.handleIngest())
+	api.GET("/stations/status", s.handleStationStatus())
+	api.GET("/events", s.handleEvents())
 
 }
 
@@ -3,17 +3,40 @@ package web
 import (
 	"github.com/apex/log"
 	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/alerts"
 	"github.com/hsmade/OSM-ARDF/pkg/database"
+	"github.com/hsmade/OSM-ARDF/pkg/quality"
+	"github.com/hsmade/OSM-ARDF/pkg/status"
+	"github.com/hsmade/OSM-ARDF/pkg/tiles"
+	"github.com/hsmade/OSM-ARDF/pkg/tracker"
 	"net/http"
+	"time"
 )
 
 type server struct {
-	router *gin.Engine
-	db     database.Database
+	router           *gin.Engine
+	db               database.Database
+	tracker          *tracker.Tracker
+	clusterDistance  float64 // metres
+	clusterMinPoints int
+	thresholds       *quality.Thresholds
+	tokens           Tokens                  // per station bearer tokens for posting measurements
+	maps             map[string]tiles.Source // base maps by name
+	events           hub                     // the push channel
+	monitor          *status.Monitor
+	recorder         *status.Recorder // counts the measurements and errors of the ingest endpoint
+	alerts           *alerts.Engine   // optional
+	hunts            huntCache        // the hunts behind the vector tiles
 }
 
 func NewServer(databaseURL string) *server {
-	s := server{router: gin.Default()}
+	s := server{
+		router:           gin.Default(),
+		tracker:          tracker.New(),
+		clusterDistance:  100,
+		clusterMinPoints: 1,
+		thresholds:       quality.New(),
+	}
 	s.routes()
 	s.db = database.New(databaseURL)
 	err := s.db.Connect()
@@ -22,9 +45,16 @@ func NewServer(databaseURL string) *server {
 	} else {
 		log.Info("connected to database")
 	}
+	s.monitor = status.NewMonitor(s.db)
+	s.recorder = status.NewRecorder(s.db)
 	return &s
 }
 
 func (s *server) Serve(addr string) error {
+	go s.recorder.Start()
+	go s.publishStatus(10 * time.Second)
+	if s.alerts != nil {
+		go s.alerts.Start()
+	}
 	return http.ListenAndServe(addr, s.router)
 }
@@ -0,0 +1,34 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"time"
+)
+
+// handleStationStatus returns whether each station is online, stale or offline, with what it last sent
+func (s *server) handleStationStatus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stations, err := s.monitor.Status()
+		if err != nil {
+			_ = c.AbortWithError(500, errors.New(fmt.Sprintf("unable to get station status: %e", err)))
+			return
+		}
+		c.JSON(200, stations)
+	}
+}
+
+// publishStatus pushes the status of the stations every interval
+func (s *server) publishStatus(interval time.Duration) {
+	for {
+		stations, err := s.monitor.Status()
+		if err != nil {
+			log.Printf("unable to get station status: %e", err)
+		} else {
+			s.Publish("status", stations)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,80 @@
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/status"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/matryer/is"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStationStatus(t *testing.T) {
+	Is := is.New(t)
+	now := time.Now()
+	db := &databasetest.Database{Lines: []*types.Line{
+		{Position: types.Position{Timestamp: now.Add(-10 * time.Second), Station: "car1", Longitude: 5, Latitude: 52}, Bearing: 90},
+		{Position: types.Position{Timestamp: now.Add(-10 * time.Minute), Station: "car2", Longitude: 5, Latitude: 52}, Bearing: 180},
+	}}
+	srv := &server{router: gin.Default(), db: db, monitor: status.NewMonitor(db)}
+	srv.routes()
+
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/stations/status", nil))
+	Is.Equal(w.Code, http.StatusOK)
+	var stations []*status.Station
+	Is.NoErr(json.Unmarshal(w.Body.Bytes(), &stations))
+	Is.Equal(len(stations), 2)
+	Is.Equal(stations[0].Station, "car1")
+	Is.Equal(stations[0].State, status.Online)
+	Is.Equal(*stations[0].Bearing, 90)
+	Is.Equal(stations[1].State, status.Offline)
+}
+
+func TestEvents(t *testing.T) {
+	Is := is.New(t)
+	srv := &server{router: gin.Default()}
+	srv.routes()
+	server := httptest.NewServer(srv.router)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/api/events")
+	Is.NoErr(err)
+	defer response.Body.Close()
+	Is.Equal(response.Header.Get("Content-Type"), "text/event-stream")
+
+	// keep publishing until the client is subscribed
+	done := make(chan bool)
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				srv.Publish("status", []*status.Station{{Station: "car1", State: status.Stale}})
+			}
+		}
+	}()
+
+	reader := bufio.NewReader(response.Body)
+	var lines []string
+	for len(lines) < 2 {
+		line, err := reader.ReadString('\n')
+		Is.NoErr(err)
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	Is.Equal(lines[0], "event:status")
+	Is.True(strings.HasPrefix(lines[1], "data:"))
+	var stations []*status.Station
+	Is.NoErr(json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data:")), &stations))
+	Is.Equal(stations[0].State, status.Stale)
+}
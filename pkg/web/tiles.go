@@ -0,0 +1,107 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/export"
+	"github.com/paulmach/orb/maptile"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// huntCacheTTL is how long the tiles of a viewport share a hunt, a map requests all its tiles at about the same time
+const huntCacheTTL = 2 * time.Second
+
+// huntCache keeps the recent hunts by their query, so the tiles of a viewport don't each compute the hunt
+type huntCache struct {
+	mutex   sync.Mutex
+	entries map[string]*huntCacheEntry
+}
+
+type huntCacheEntry struct {
+	done   chan struct{} // closed when the hunt is computed
+	hunt   *export.Hunt
+	err    error
+	bucket int64 // the period of huntCacheTTL in which the hunt was computed
+}
+
+// handleTiles renders a layer of the hunt as a Mapbox Vector Tile, at /tiles/{layer}/{z}/{x}/{y}.mvt.
+// The layer all renders all layers.
+func (s *server) handleTiles() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		layer := c.Param("layer")
+		if layer == "all" {
+			layer = ""
+		}
+		if !strings.HasSuffix(c.Param("y"), ".mvt") {
+			_ = c.AbortWithError(404, errors.New("tiles should end with .mvt"))
+			return
+		}
+		z, errZ := strconv.ParseUint(c.Param("z"), 10, 32)
+		x, errX := strconv.ParseUint(c.Param("x"), 10, 32)
+		y, errY := strconv.ParseUint(strings.TrimSuffix(c.Param("y"), ".mvt"), 10, 32)
+		if errZ != nil || errX != nil || errY != nil {
+			_ = c.AbortWithError(400, errors.New("z, x and y must be numbers"))
+			return
+		}
+		tile := maptile.New(uint32(x), uint32(y), maptile.Zoom(z))
+		if z > 30 || !tile.Valid() {
+			_ = c.AbortWithError(400, errors.New(fmt.Sprintf("invalid tile %d/%d/%d", z, x, y)))
+			return
+		}
+
+		hunt, err := s.cachedHunt(c)
+		if err != nil {
+			_ = c.AbortWithError(500, err)
+			return
+		}
+		data, err := hunt.MVT(layer, tile)
+		if err != nil {
+			_ = c.AbortWithError(400, errors.New(fmt.Sprintf("unable to create tile: %v", err)))
+			return
+		}
+		c.Header("Cache-Control", "no-cache")
+		c.Data(200, "application/vnd.mapbox-vector-tile", data)
+	}
+}
+
+// cachedHunt returns the hunt for the query of the request, computing it at most once per huntCacheTTL.
+// Concurrent requests for the same query wait for the hunt that is being computed instead of computing their own,
+// requests for other queries don't wait.
+func (s *server) cachedHunt(c *gin.Context) (*export.Hunt, error) {
+	key := strings.Join([]string{c.Query("seconds"), c.Query("distance"), c.Query("min_angle"), c.Query("max_dilution")}, "|")
+	bucket := time.Now().UnixNano() / int64(huntCacheTTL)
+
+	s.hunts.mutex.Lock()
+	if entry, ok := s.hunts.entries[key]; ok && entry.bucket == bucket {
+		s.hunts.mutex.Unlock()
+		<-entry.done
+		return entry.hunt, entry.err
+	}
+	for cached, entry := range s.hunts.entries {
+		if entry.bucket != bucket {
+			delete(s.hunts.entries, cached)
+		}
+	}
+	if s.hunts.entries == nil {
+		s.hunts.entries = map[string]*huntCacheEntry{}
+	}
+	entry := &huntCacheEntry{done: make(chan struct{}), bucket: bucket}
+	s.hunts.entries[key] = entry
+	s.hunts.mutex.Unlock()
+
+	entry.hunt, entry.err = s.hunt(c)
+	close(entry.done)
+	if entry.err != nil {
+		// the next request tries again
+		s.hunts.mutex.Lock()
+		if s.hunts.entries[key] == entry {
+			delete(s.hunts.entries, key)
+		}
+		s.hunts.mutex.Unlock()
+	}
+	return entry.hunt, entry.err
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/quality"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/matryer/is"
+	"github.com/paulmach/orb/encoding/mvt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTiles(t *testing.T) {
+	Is := is.New(t)
+	now := time.Now()
+	db := &databasetest.Database{Lines: []*types.Line{{Position: types.Position{Timestamp: now, Station: "south", Longitude: 5, Latitude: 51.99}, LongitudeEnd: 5, LatitudeEnd: 52.2}}}
+	srv := &server{
+		router:     gin.Default(),
+		db:         db,
+		thresholds: quality.New(),
+	}
+	srv.routes()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	// the tile with 5, 52 at zoom 12
+	w := get("/api/tiles/lines/12/2104/1350.mvt?seconds=60")
+	Is.Equal(w.Code, http.StatusOK)
+	Is.Equal(w.Header().Get("Content-Type"), "application/vnd.mapbox-vector-tile")
+	layers, err := mvt.Unmarshal(w.Body.Bytes())
+	Is.NoErr(err)
+	Is.Equal(len(layers), 1)
+	Is.Equal(len(layers[0].Features), 1)
+
+	w = get("/api/tiles/all/12/0/0.mvt?seconds=60")
+	Is.Equal(w.Code, http.StatusOK)
+	layers, err = mvt.Unmarshal(w.Body.Bytes())
+	Is.NoErr(err)
+	Is.Equal(len(layers), 4)
+	Is.Equal(len(layers[1].Features), 0)
+
+	// the tiles of a viewport share the hunt, at most 2 are computed when the requests straddle the cache period
+	for x := 2100; x < 2110; x++ {
+		Is.Equal(get(fmt.Sprintf("/api/tiles/all/12/%d/1350.mvt?seconds=60", x)).Code, http.StatusOK)
+	}
+	Is.True(db.LineQueries() <= 2)
+
+	// a hunt that is being computed for another query doesn't hold up the tiles
+	srv.hunts.mutex.Lock()
+	srv.hunts.entries["120|||"] = &huntCacheEntry{done: make(chan struct{}), bucket: time.Now().UnixNano() / int64(huntCacheTTL)}
+	srv.hunts.mutex.Unlock()
+	done := make(chan int)
+	go func() {
+		done <- get("/api/tiles/all/12/2104/1350.mvt?seconds=30").Code
+	}()
+	select {
+	case code := <-done:
+		Is.Equal(code, http.StatusOK)
+	case <-time.After(5 * time.Second):
+		t.Fatal("tile request waited for the hunt of another query")
+	}
+
+	Is.Equal(get("/api/tiles/lines/12/2104/1350.png?seconds=60").Code, http.StatusNotFound)
+	Is.Equal(get("/api/tiles/lines/12/2104/x.mvt?seconds=60").Code, http.StatusBadRequest)
+	Is.Equal(get("/api/tiles/lines/2/4/0.mvt?seconds=60").Code, http.StatusBadRequest)
+	Is.Equal(get("/api/tiles/heatmap/12/2104/1350.mvt?seconds=60").Code, http.StatusBadRequest)
+	Is.Equal(get("/api/tiles/lines/12/2104/1350.mvt?seconds=60&min_angle=x").Code, http.StatusInternalServerError)
+}
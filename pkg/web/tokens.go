@@ -0,0 +1,75 @@
+package web
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Tokens maps a station to the bearer token it uses to post measurements
+type Tokens map[string]string
+
+// LoadTokens reads a file with a station and its token on each line, separated by white space.
+// Empty lines and lines starting with # are skipped.
+func LoadTokens(path string) (Tokens, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tokens := Tokens{}
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected a station and a token", number)
+		}
+		tokens[fields[0]] = fields[1]
+	}
+	return tokens, scanner.Err()
+}
+
+// station returns the station that the token belongs to
+func (t Tokens) station(token string) (string, bool) {
+	found := ""
+	for station, stationToken := range t {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(stationToken)) == 1 {
+			found = station
+		}
+	}
+	return found, found != ""
+}
+
+// SetTokens sets the tokens that authenticate the stations that post measurements
+func (s *server) SetTokens(tokens Tokens) {
+	s.tokens = tokens
+}
+
+// authenticate only lets requests with a known bearer token through, and stores the station in the context
+func (s *server) authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+		station, ok := s.tokens.station(strings.TrimPrefix(header, "Bearer "))
+		if !ok {
+			c.Header("WWW-Authenticate", "Bearer error=\"invalid_token\"")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid bearer token"})
+			return
+		}
+		c.Set("station", station)
+		c.Next()
+	}
+}
@@ -0,0 +1,104 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/paulmach/go.geojson"
+	"log"
+	"strconv"
+	"time"
+)
+
+// trackHalfLives is the number of half lives of the tracker that are fetched when no seconds are given,
+// after which a bearing weighs less than 1/16th of a fresh one
+const trackHalfLives = 4
+
+// handleTrack tracks the transmitter over the lines of the last seconds.
+// Within that window the tracker weighs each bearing by its age, halving its weight every half life,
+// so seconds only cuts off bearings that are too old to matter.
+// When seconds is left out, it defaults to trackHalfLives half lives.
+func (s *server) handleTrack() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		since, err := s.trackWindow(c.Query("seconds"))
+		if err != nil {
+			_ = c.AbortWithError(500, err)
+			return
+		}
+		lines, err := s.db.GetLines(since)
+		if err != nil {
+			_ = c.AbortWithError(500, errors.New(fmt.Sprintf("unable to get lines: %e", err)))
+			return
+		}
+		track, err := s.tracker.Track(lines, time.Now())
+		if err != nil {
+			_ = c.AbortWithError(500, errors.New(fmt.Sprintf("unable to track transmitter: %e", err)))
+			return
+		}
+		log.Printf("tracked transmitter over %d lines", len(lines))
+		c.String(200, string(formatTrack(track)))
+	}
+}
+
+// trackWindow returns how far back to fetch lines for the tracker
+func (s *server) trackWindow(seconds string) (time.Duration, error) {
+	if seconds == "" {
+		return trackHalfLives * s.tracker.HalfLife, nil
+	}
+	since, err := strconv.Atoi(seconds)
+	if err != nil {
+		return 0, errors.New("seconds must be a number")
+	}
+	return time.Duration(since) * time.Second, nil
+}
+
+func formatTrack(track *types.Track) []byte {
+	fc := geojson.NewFeatureCollection()
+
+	positionFeature := geojson.NewPointFeature([]float64{track.Longitude, track.Latitude})
+	positionFeature.Properties = map[string]interface{}{
+		"id":          "position",
+		"timestamp":   track.Timestamp,
+		"uncertainty": track.Radius,
+		"speed":       track.Speed,
+		"course":      track.Course,
+	}
+	fc.AddFeature(positionFeature)
+
+	for _, feature := range []*geojson.Feature{
+		formatEstimates("history", track.History),
+		formatEstimates("prediction", track.Prediction),
+	} {
+		if feature != nil {
+			fc.AddFeature(feature)
+		}
+	}
+
+	rawJSON, err := fc.MarshalJSON()
+	if err != nil {
+		log.Printf("error marshalling json: %e", err)
+		return []byte("error marshalling into json")
+	}
+	log.Printf("raw json: %s", string(rawJSON))
+	return rawJSON
+}
+
+// formatEstimates returns the estimates as a line with the uncertainty of each point, or nil for less than 2 estimates
+func formatEstimates(id string, estimates []*types.Estimate) *geojson.Feature {
+	if len(estimates) < 2 {
+		return nil
+	}
+	var coordinates [][]float64
+	var uncertainty []float64
+	for _, estimate := range estimates {
+		coordinates = append(coordinates, []float64{estimate.Longitude, estimate.Latitude})
+		uncertainty = append(uncertainty, estimate.Radius)
+	}
+	lineFeature := geojson.NewLineStringFeature(coordinates)
+	lineFeature.Properties = map[string]interface{}{
+		"id":          id,
+		"uncertainty": uncertainty,
+	}
+	return lineFeature
+}
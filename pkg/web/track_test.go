@@ -0,0 +1,25 @@
+package web
+
+import (
+	"github.com/hsmade/OSM-ARDF/pkg/tracker"
+	"github.com/matryer/is"
+	"testing"
+	"time"
+)
+
+func TestTrackWindow(t *testing.T) {
+	Is := is.New(t)
+	srv := &server{tracker: tracker.New()}
+	srv.tracker.HalfLife = time.Minute
+
+	since, err := srv.trackWindow("")
+	Is.NoErr(err)
+	Is.Equal(since, 4*time.Minute)
+
+	since, err = srv.trackWindow("90")
+	Is.NoErr(err)
+	Is.Equal(since, 90*time.Second)
+
+	_, err = srv.trackWindow("soon")
+	Is.True(err != nil)
+}
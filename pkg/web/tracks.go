@@ -0,0 +1,116 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/export"
+	"github.com/paulmach/go.geojson"
+	"log"
+	"strconv"
+	"time"
+)
+
+// defaultTolerance is the distance in metres a simplified track may deviate from the positions
+const defaultTolerance = 10.0
+
+// handleTracks returns a line per station of its positions, simplified by the tolerance query parameter in metres
+func (s *server) handleTracks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hunt, err := s.positionsHunt(c)
+		if err != nil {
+			_ = c.AbortWithError(500, err)
+			return
+		}
+		tolerance := defaultTolerance
+		if value := c.Query("tolerance"); value != "" {
+			tolerance, err = strconv.ParseFloat(value, 64)
+			if err != nil || tolerance < 0 {
+				_ = c.AbortWithError(500, errors.New("tolerance must be a positive number"))
+				return
+			}
+		}
+		tracks := hunt.Tracks(tolerance)
+		log.Printf("simplified %d positions into %d tracks", len(hunt.Positions), len(tracks))
+		c.String(200, string(formatTracks(tracks)))
+	}
+}
+
+// handleLatestPositions returns the latest position of each station, with its speed and heading
+func (s *server) handleLatestPositions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hunt, err := s.positionsHunt(c)
+		if err != nil {
+			_ = c.AbortWithError(500, err)
+			return
+		}
+		c.String(200, string(formatLatest(hunt.Latest())))
+	}
+}
+
+// positionsHunt returns a hunt with only the positions of the last seconds
+func (s *server) positionsHunt(c *gin.Context) (*export.Hunt, error) {
+	since, err := strconv.Atoi(c.Query("seconds"))
+	if err != nil {
+		return nil, errors.New("seconds must be a number")
+	}
+	positions, err := s.db.GetPositions(time.Duration(since) * time.Second)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("unable to get positions: %e", err))
+	}
+	return &export.Hunt{Positions: positions}, nil
+}
+
+func formatTracks(tracks []*export.Track) []byte {
+	fc := geojson.NewFeatureCollection()
+	for _, track := range tracks {
+		var coordinates [][]float64
+		for _, point := range track.Path {
+			coordinates = append(coordinates, []float64{point.Lon(), point.Lat()})
+		}
+		var feature *geojson.Feature
+		if len(coordinates) == 1 {
+			// a line needs two points, a station that didn't move is a point
+			feature = geojson.NewPointFeature(coordinates[0])
+		} else {
+			feature = geojson.NewLineStringFeature(coordinates)
+		}
+		feature.Properties = map[string]interface{}{
+			"id":        track.Station,
+			"station":   track.Station,
+			"colour":    export.StationColour(track.Station),
+			"start":     track.Start,
+			"end":       track.End,
+			"positions": track.Positions,
+		}
+		fc.AddFeature(feature)
+	}
+	return marshal(fc)
+}
+
+func formatLatest(movements []*export.Movement) []byte {
+	fc := geojson.NewFeatureCollection()
+	for _, movement := range movements {
+		feature := geojson.NewPointFeature([]float64{movement.Longitude, movement.Latitude})
+		feature.Properties = map[string]interface{}{
+			"id":        movement.Station,
+			"station":   movement.Station,
+			"timestamp": movement.Timestamp,
+			"speed":     movement.Speed,
+		}
+		if movement.HasHeading {
+			feature.Properties["heading"] = movement.Heading
+		}
+		fc.AddFeature(feature)
+	}
+	return marshal(fc)
+}
+
+func marshal(fc *geojson.FeatureCollection) []byte {
+	rawJSON, err := fc.MarshalJSON()
+	if err != nil {
+		log.Printf("error marshalling json: %e", err)
+		return []byte("error marshalling into json")
+	}
+	return rawJSON
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hsmade/OSM-ARDF/pkg/database/databasetest"
+	"github.com/hsmade/OSM-ARDF/pkg/types"
+	"github.com/matryer/is"
+	"github.com/paulmach/go.geojson"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTracks(t *testing.T) {
+	Is := is.New(t)
+	now := time.Now()
+	db := &databasetest.Database{}
+	// car1 drives north and turns east, car2 is parked
+	for i := 0; i <= 20; i++ {
+		longitude, latitude := 5.0, 52.0+float64(i)*0.0001
+		if i > 10 {
+			longitude, latitude = 5.0+float64(i-10)*0.0001, 52.001
+		}
+		db.Positions = append(db.Positions, &types.Position{Timestamp: now.Add(time.Duration(i-20) * time.Second), Station: "car1", Longitude: longitude, Latitude: latitude})
+	}
+	db.Positions = append(db.Positions, &types.Position{Timestamp: now, Station: "car2", Longitude: 5.1, Latitude: 52.1})
+	srv := &server{router: gin.Default(), db: db}
+	srv.routes()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/api/tracks?seconds=60&tolerance=2")
+	Is.Equal(w.Code, http.StatusOK)
+	fc, err := geojson.UnmarshalFeatureCollection(w.Body.Bytes())
+	Is.NoErr(err)
+	Is.Equal(len(fc.Features), 2)
+	Is.True(fc.Features[0].Geometry.IsLineString())
+	Is.Equal(len(fc.Features[0].Geometry.LineString), 3) // start, corner and end
+	Is.Equal(fc.Features[0].Properties["station"], "car1")
+	Is.Equal(fc.Features[0].Properties["positions"], 21.0)
+	Is.True(fc.Features[1].Geometry.IsPoint())
+
+	Is.Equal(get("/api/tracks?seconds=60&tolerance=-1").Code, http.StatusInternalServerError)
+	Is.Equal(get("/api/tracks?tolerance=2").Code, http.StatusInternalServerError)
+
+	w = get("/api/positions/latest?seconds=60")
+	Is.Equal(w.Code, http.StatusOK)
+	fc, err = geojson.UnmarshalFeatureCollection(w.Body.Bytes())
+	Is.NoErr(err)
+	Is.Equal(len(fc.Features), 2)
+	Is.Equal(fc.Features[0].Geometry.Point, []float64{5.001, 52.001})
+	// over the last 30 seconds car1 went north east, compressed by the latitude
+	heading := fc.Features[0].Properties["heading"].(float64)
+	Is.True(heading > 25 && heading < 40)
+	Is.True(fc.Features[0].Properties["speed"].(float64) > 5)
+	_, hasHeading := fc.Features[1].Properties["heading"]
+	Is.True(!hasHeading)
+}